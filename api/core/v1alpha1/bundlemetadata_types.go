@@ -24,7 +24,12 @@ import (
 )
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:resource:scope=Cluster,shortName=bm
+//+kubebuilder:printcolumn:name="Catalog",type=string,JSONPath=`.spec.catalog.name`
+//+kubebuilder:printcolumn:name="Package",type=string,JSONPath=`.spec.package`
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`
+//+kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.image`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // BundleMetadata is the Schema for the bundlemetadata API
 type BundleMetadata struct {
@@ -50,6 +55,9 @@ type BundleMetadataSpec struct {
 	// Catalog is the name of the Catalog that provides this bundle
 	Catalog corev1.LocalObjectReference `json:"catalog"`
 
+	// Name is the name of the bundle in the catalog
+	Name string `json:"name,omitempty"`
+
 	// Package is the name of the package that provides this bundle
 	Package string `json:"package"`
 
@@ -61,6 +69,15 @@ type BundleMetadataSpec struct {
 
 	// RelatedImages are the RelatedImages in the bundle
 	RelatedImages []RelatedImage `json:"relatedImages,omitempty"`
+
+	// BundleObjectRefs are references to the BundleObjects that hold the
+	// olm.bundle.object properties of the bundle that are too large to be
+	// kept inline in Properties. They are only set when the Catalog
+	// preserves bundle objects.
+	BundleObjectRefs []corev1.LocalObjectReference `json:"bundleObjectRefs,omitempty"`
+
+	// Deprecation is set when the catalog deprecates the bundle
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 type Property struct {
@@ -81,7 +98,37 @@ type RelatedImage struct {
 }
 
 // BundleMetadataStatus defines the observed state of BundleMetadata
-type BundleMetadataStatus struct{}
+type BundleMetadataStatus struct {
+	// Version is the version of the bundle, as declared by its olm.package property
+	Version string `json:"version,omitempty"`
+
+	// ProvidedGVKs are the group/version/kinds provided by the bundle, as
+	// declared by its olm.gvk properties
+	ProvidedGVKs []GroupVersionKind `json:"providedGVKs,omitempty"`
+
+	// RequiredPackages are the packages required by the bundle, as declared
+	// by its olm.package.required properties
+	RequiredPackages []RequiredPackage `json:"requiredPackages,omitempty"`
+
+	// Channels are the names of the channels of the bundle's package that
+	// contain the bundle
+	Channels []string `json:"channels,omitempty"`
+}
+
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+type RequiredPackage struct {
+	// PackageName is the name of the required package
+	PackageName string `json:"packageName"`
+
+	// VersionRange is the semver range of the required package's versions
+	// that satisfy the requirement
+	VersionRange string `json:"versionRange"`
+}
 
 func init() {
 	SchemeBuilder.Register(&BundleMetadata{}, &BundleMetadataList{})
@@ -0,0 +1,66 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster,shortName=bo
+//+kubebuilder:printcolumn:name="Catalog",type=string,JSONPath=`.spec.catalog.name`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// BundleObject is the Schema for the bundleobjects API. A BundleObject holds
+// the contents of an olm.bundle.object property that is too large to be kept
+// inline in the BundleMetadata that references it. BundleObjects are named
+// after the digest of their contents, so bundles of a Catalog that share an
+// object reference the same BundleObject.
+type BundleObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BundleObjectSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// BundleObjectList contains a list of BundleObject
+type BundleObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []BundleObject `json:"items"`
+}
+
+// BundleObjectSpec defines the desired state of BundleObject
+type BundleObjectSpec struct {
+	// Catalog is the name of the Catalog that provides this object
+	Catalog corev1.LocalObjectReference `json:"catalog"`
+
+	// Digest is the sha256 digest of Data
+	Digest string `json:"digest"`
+
+	// Data is the content of the object, e.g. the manifest of a
+	// ClusterServiceVersion or CustomResourceDefinition
+	Data []byte `json:"data"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BundleObject{}, &BundleObjectList{})
+}
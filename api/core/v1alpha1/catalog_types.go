@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,7 +27,10 @@ import (
 type SourceType string
 
 const (
-	SourceTypeImage SourceType = "image"
+	SourceTypeImage      SourceType = "image"
+	SourceTypeGit        SourceType = "git"
+	SourceTypeHTTP       SourceType = "http"
+	SourceTypeConfigMaps SourceType = "configMaps"
 
 	TypeUnpacked = "Unpacked"
 
@@ -34,6 +38,18 @@ const (
 	ReasonUnpacking        = "Unpacking"
 	ReasonUnpackSuccessful = "UnpackSuccessful"
 	ReasonUnpackFailed     = "UnpackFailed"
+	// ReasonVerificationFailed is the reason of the Unpacked condition when
+	// the catalog image is missing a signature trusted by its verification
+	// policy, or carries only invalid signatures.
+	ReasonVerificationFailed = "VerificationFailed"
+	// ReasonUnpackTimeout is the reason of the Unpacked condition when the
+	// catalog was not unpacked within its unpack timeout.
+	ReasonUnpackTimeout = "UnpackTimeout"
+
+	TypeSourceDrifted = "SourceDrifted"
+
+	ReasonDigestChanged   = "DigestChanged"
+	ReasonDigestUnchanged = "DigestUnchanged"
 
 	PhasePending   = "Pending"
 	PhaseUnpacking = "Unpacking"
@@ -42,8 +58,14 @@ const (
 )
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:resource:scope=Cluster,shortName=cat
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.source.type`
+//+kubebuilder:printcolumn:name="Resolved Image",type=string,JSONPath=`.status.resolvedSource.image.ref`
+//+kubebuilder:printcolumn:name="Resolved Commit",type=string,JSONPath=`.status.resolvedSource.git.ref.commit`,priority=1
+//+kubebuilder:printcolumn:name="Resolved SHA256",type=string,JSONPath=`.status.resolvedSource.http.sha256`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Catalog is the Schema for the Catalogs API
 type Catalog struct {
@@ -69,6 +91,78 @@ type CatalogSpec struct {
 	// Source is the source of a Catalog that contains Operators' metadata in the FBC format
 	// https://olm.operatorframework.io/docs/reference/file-based-catalogs/#docs
 	Source CatalogSource `json:"source"`
+
+	// Priority is the priority of the Catalog's packages over the packages of
+	// the same name in other Catalogs. The package of the Catalog with the
+	// highest priority is preferred, and ties are broken in favor of the
+	// Catalog whose name sorts first. Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// BundleObjects configures the preservation of the olm.bundle.object
+	// properties of the Catalog's bundles, which contain the manifests of
+	// their ClusterServiceVersions, CustomResourceDefinitions and other
+	// objects. When unset, these properties are dropped from the bundles'
+	// BundleMetadata.
+	// +optional
+	BundleObjects *BundleObjectsConfig `json:"bundleObjects,omitempty"`
+
+	// Filter selects the packages, channels and bundles of the catalog's
+	// contents that are surfaced on the cluster. When unset, all of the
+	// contents are surfaced.
+	// +optional
+	Filter *CatalogFilter `json:"filter,omitempty"`
+
+	// Suspend stops the Catalog from being unpacked, including retries of
+	// failed unpacks and polls of its source. Contents that were already
+	// unpacked continue to be served.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// CatalogFilter selects the packages, channels and bundles of a catalog's
+// contents. A package is selected when it is included, or when no packages
+// are included, and it is not excluded.
+type CatalogFilter struct {
+	// Include is the list of packages to select. When empty, all packages
+	// that are not excluded are selected.
+	// +optional
+	Include []PackageFilter `json:"include,omitempty"`
+
+	// Exclude is the list of names of packages not to select.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// PackageFilter selects a package and, optionally, a subset of its channels
+// and bundles.
+type PackageFilter struct {
+	// Name is the name of the package
+	Name string `json:"name"`
+
+	// Channels is the list of names of the package's channels to select.
+	// When empty, all of the package's channels are selected.
+	// +optional
+	Channels []string `json:"channels,omitempty"`
+
+	// VersionRange is a semver range, e.g. ">=1.2.0 <2.0.0", of the versions
+	// of the package's bundles to select. When set, only the bundles whose
+	// olm.package version is in range are selected. When empty, all of the
+	// bundles of the selected channels are selected.
+	// +optional
+	VersionRange string `json:"versionRange,omitempty"`
+}
+
+// BundleObjectsConfig configures how the olm.bundle.object properties of a
+// Catalog's bundles are preserved
+type BundleObjectsConfig struct {
+	// MaxInlineSize is the maximum size, in bytes, of an olm.bundle.object
+	// property value that is kept inline in the properties of its
+	// BundleMetadata. Larger values are stored in a BundleObject that is
+	// referenced from the BundleMetadata instead. Defaults to 4096.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxInlineSize *int32 `json:"maxInlineSize,omitempty"`
 }
 
 // CatalogStatus defines the observed state of Catalog
@@ -76,6 +170,41 @@ type CatalogStatus struct {
 	// Conditions store the status conditions of the Catalog instances
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
 
+	// ContentDigest is the digest of the unpacked catalog contents that the
+	// Catalog's Package and BundleMetadata resources were last synced from.
+	// Unpacked contents with the same digest are not synced again.
+	ContentDigest string `json:"contentDigest,omitempty"`
+
+	// ContentURL is the URL at which the file-based catalog contents of the
+	// Catalog are served, as a stream of JSON blobs.
+	ContentURL string `json:"contentURL,omitempty"`
+
+	// ObservedGeneration is the generation of the Catalog that its Package
+	// and BundleMetadata resources were last synced for.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LatestImageDigest is the digest that the image reference of a Catalog
+	// whose digest policy is ReportDrift pointed to when it was last checked.
+	// It differs from the digest of the resolved source when the reference has
+	// drifted from the unpacked image.
+	LatestImageDigest string `json:"latestImageDigest,omitempty"`
+
+	// PinnedImage is the image digest that a Catalog whose digest policy is
+	// ReportDrift was last unpacked from. Unlike the resolved source, it is
+	// kept while the Catalog is pending, unpacking or failing, so that the
+	// Catalog keeps unpacking the same digest until its image reference is
+	// updated.
+	PinnedImage *PinnedImage `json:"pinnedImage,omitempty"`
+
+	// UnpackAttempts is the number of consecutive attempts to unpack and sync
+	// the Catalog that have failed since it was last unpacked. Failed attempts
+	// are retried with an exponential backoff.
+	UnpackAttempts int32 `json:"unpackAttempts,omitempty"`
+
+	// LastUnpackAttemptTime is the time at which the last of the
+	// UnpackAttempts failed.
+	LastUnpackAttemptTime *metav1.Time `json:"lastUnpackAttemptTime,omitempty"`
+
 	ResolvedSource *CatalogSource `json:"resolvedSource,omitempty"`
 	Phase          string         `json:"phase,omitempty"`
 }
@@ -86,6 +215,15 @@ type CatalogSource struct {
 	Type SourceType `json:"type"`
 	// Image is the catalog image that backs the content of this catalog.
 	Image *ImageSource `json:"image,omitempty"`
+	// Git is the git repository that backs the content of this catalog.
+	Git *GitSource `json:"git,omitempty"`
+	// HTTP is the gzipped tarball served over HTTP(S) that backs the content of this catalog.
+	HTTP *HTTPSource `json:"http,omitempty"`
+	// ConfigMaps is a list of config map references and their relative
+	// directory paths that represent the content of this catalog. The
+	// ConfigMaps are read when the Catalog is reconciled; updating a
+	// ConfigMap does not by itself trigger the Catalog to be unpacked again.
+	ConfigMaps []ConfigMapSource `json:"configMaps,omitempty"`
 }
 
 // ImageSource contains information required for sourcing a Catalog from an OCI image
@@ -94,6 +232,131 @@ type ImageSource struct {
 	Ref string `json:"ref"`
 	// PullSecret contains the name of the image pull secret in the namespace that catalogd is deployed.
 	PullSecret string `json:"pullSecret,omitempty"`
+	// PollInterval indicates the interval at which the image source should be polled for new content.
+	// When set, the image reference is periodically resolved and the catalog is unpacked again whenever
+	// it points to a different digest than the one in the Catalog's resolved source. When unset, the
+	// image is only unpacked when the Catalog spec changes. The interval must be at least one minute.
+	// +optional
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+	// Verification configures the signatures that the catalog image must carry
+	// before its contents are trusted. Signatures are expected in the format
+	// produced by cosign and are looked up in the image's repository. When unset,
+	// signatures are not verified.
+	// +optional
+	Verification *ImageVerification `json:"verification,omitempty"`
+	// DigestPolicy controls how the digest that the catalog image is unpacked
+	// from is chosen. When unset, the digest that Ref points to is unpacked and,
+	// if PollInterval is set, followed as Ref moves. Require rejects references
+	// that do not contain a digest. ReportDrift keeps the digest that was last
+	// unpacked rather than following Ref, and reports in the SourceDrifted
+	// condition when Ref points to a different digest, checking it every
+	// PollInterval if set. Update Ref to unpack the digest it drifted to.
+	// +kubebuilder:validation:Enum=Require;ReportDrift
+	// +optional
+	DigestPolicy DigestPolicy `json:"digestPolicy,omitempty"`
+	// UnpackTimeout is the time that the pod unpacking the catalog image may
+	// take to complete, including pulling the image, before it is deleted and
+	// the unpack fails with the UnpackTimeout reason to be retried. When unset,
+	// the default unpack timeout of catalogd is used.
+	// +optional
+	UnpackTimeout *metav1.Duration `json:"unpackTimeout,omitempty"`
+}
+
+// PinnedImage is the digest that the image reference of a Catalog resolved
+// to when it was last unpacked.
+type PinnedImage struct {
+	// Ref is the image reference of the Catalog's source that was resolved.
+	Ref string `json:"ref"`
+	// Digest is the digest that Ref resolved to.
+	Digest string `json:"digest"`
+}
+
+// DigestPolicy controls how the digest of a catalog image is chosen.
+type DigestPolicy string
+
+const (
+	// DigestPolicyRequire requires the image reference to contain a digest.
+	DigestPolicyRequire DigestPolicy = "Require"
+	// DigestPolicyReportDrift keeps the unpacked digest and reports when the
+	// image reference points to a different one.
+	DigestPolicyReportDrift DigestPolicy = "ReportDrift"
+)
+
+// ImageVerification is a policy that a catalog image satisfies when it carries
+// a valid signature by any of the configured public keys.
+type ImageVerification struct {
+	// PublicKeys references a secret in the namespace that catalogd is deployed
+	// whose values are PEM encoded public keys trusted to sign the catalog image.
+	PublicKeys corev1.LocalObjectReference `json:"publicKeys"`
+}
+
+// GitSource contains information required for sourcing a Catalog from a git repository
+type GitSource struct {
+	// Repository is a URL link to the git repository containing the catalog.
+	// Repository is required and the URL should be parsable by a standard git tool.
+	Repository string `json:"repository"`
+	// Directory refers to the location of the catalog within the git repository.
+	// Directory is optional and if not set defaults to the root of the repository.
+	Directory string `json:"directory,omitempty"`
+	// Ref configures the git source to clone a specific branch, tag, or commit
+	// from the specified repo. Ref is required, and exactly one field within Ref
+	// is required. Setting more than one field or zero fields will result in an
+	// error.
+	Ref GitRef `json:"ref"`
+	// Auth configures the authorization method if necessary.
+	Auth Authorization `json:"auth,omitempty"`
+}
+
+// GitRef identifies the revision of a git repository that contains a Catalog's contents
+type GitRef struct {
+	// Branch refers to the branch to checkout from the repository.
+	// The Branch should contain the catalog in the specified Directory.
+	Branch string `json:"branch,omitempty"`
+	// Tag refers to the tag to checkout from the repository.
+	// The Tag should contain the catalog in the specified Directory.
+	Tag string `json:"tag,omitempty"`
+	// Commit refers to the commit to checkout from the repository.
+	// The Commit should contain the catalog in the specified Directory.
+	Commit string `json:"commit,omitempty"`
+}
+
+// HTTPSource contains information required for sourcing a Catalog from a gzipped tarball served over HTTP(S)
+type HTTPSource struct {
+	// URL is the location of a gzipped tarball containing the catalog contents.
+	URL string `json:"url"`
+	// SHA256 is the expected hex-encoded sha256 digest of the tarball. When set, a downloaded
+	// tarball whose digest does not match is rejected.
+	SHA256 string `json:"sha256,omitempty"`
+	// CertificateData contains the PEM data of additional certificate authorities to trust when
+	// connecting to the URL, alongside the system's trusted roots.
+	CertificateData string `json:"certificateData,omitempty"`
+	// Auth configures the authorization method if necessary.
+	Auth Authorization `json:"auth,omitempty"`
+}
+
+// ConfigMapSource contains information required for sourcing a part of a Catalog's contents from a ConfigMap
+type ConfigMapSource struct {
+	// ConfigMap is a reference to a ConfigMap in the namespace that catalogd is deployed.
+	// Each key of the ConfigMap is unpacked as a file of the same name.
+	ConfigMap corev1.LocalObjectReference `json:"configMap"`
+	// Path is the relative directory path within the catalog where the files
+	// from the ConfigMap will be placed. Path is optional and if not set
+	// defaults to the root of the catalog.
+	Path string `json:"path,omitempty"`
+}
+
+// Authorization contains the information needed to authenticate with a Catalog's source
+type Authorization struct {
+	// Secret contains reference to the secret that has authorization information and is in the namespace that catalogd is deployed.
+	// For the http(s) scheme, the secret is expected to contain either `data.username` and `data.password` for the username and password, respectively,
+	// or `data.token` for a bearer token.
+	// For the ssh scheme, the secret is expected to contain `data.ssh-privatekey` and `data.ssh-knownhosts` for the ssh privatekey and the host entry in the known_hosts file, respectively.
+	Secret corev1.LocalObjectReference `json:"secret,omitempty"`
+	// InsecureSkipVerify controls whether a client verifies the server's certificate chain and host name. If InsecureSkipVerify
+	// is true, the clone operation will accept any certificate presented by the server and any host name in that
+	// certificate. In this mode, TLS is susceptible to machine-in-the-middle attacks unless custom verification is
+	// used. This should be used only for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
 
 func init() {
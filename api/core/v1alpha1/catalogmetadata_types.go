@@ -0,0 +1,77 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Catalog",type=string,JSONPath=`.spec.catalog.name`
+//+kubebuilder:printcolumn:name="Schema",type=string,JSONPath=`.spec.schema`
+//+kubebuilder:printcolumn:name="Package",type=string,JSONPath=`.spec.package`
+//+kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CatalogMetadata is the Schema for the catalogmetadata API. A CatalogMetadata
+// holds a blob of a Catalog's contents whose schema catalogd does not
+// otherwise interpret, such as the custom schemas that file-based catalogs
+// allow.
+type CatalogMetadata struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CatalogMetadataSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CatalogMetadataList contains a list of CatalogMetadata
+type CatalogMetadataList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []CatalogMetadata `json:"items"`
+}
+
+// CatalogMetadataSpec defines the desired state of CatalogMetadata
+type CatalogMetadataSpec struct {
+	// Catalog is the name of the Catalog that provides this blob
+	Catalog corev1.LocalObjectReference `json:"catalog"`
+
+	// Schema is the schema of the blob
+	Schema string `json:"schema"`
+
+	// Package is the name of the package that the blob belongs to, if any
+	Package string `json:"package,omitempty"`
+
+	// Name is the name of the blob, if any
+	Name string `json:"name,omitempty"`
+
+	// Content is the raw JSON content of the blob
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Content json.RawMessage `json:"content"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CatalogMetadata{}, &CatalogMetadataList{})
+}
@@ -22,7 +22,11 @@ import (
 )
 
 //+kubebuilder:object:root=true
-//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:resource:scope=Cluster,shortName=pkg
+//+kubebuilder:printcolumn:name="Catalog",type=string,JSONPath=`.spec.catalog.name`
+//+kubebuilder:printcolumn:name="Package",type=string,JSONPath=`.spec.packageName`
+//+kubebuilder:printcolumn:name="Default Channel",type=string,JSONPath=`.spec.defaultChannel`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Package is the Schema for the packages API
 type Package struct {
@@ -64,6 +68,9 @@ type PackageSpec struct {
 	// default channel will be installed if no other channel is explicitly given. If the package
 	// has a single channel, then that channel is implicitly the default.
 	DefaultChannel string `json:"defaultChannel"`
+
+	// Deprecation is set when the catalog deprecates the package
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 // PackageChannel defines a single channel under a package, pointing to a version of that
@@ -74,6 +81,9 @@ type PackageChannel struct {
 
 	// Entries is all the channel entries within a channel
 	Entries []ChannelEntry `json:"entries"`
+
+	// Deprecation is set when the catalog deprecates the channel
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 type ChannelEntry struct {
@@ -83,6 +93,13 @@ type ChannelEntry struct {
 	SkipRange string   `json:"skipRange,omitempty"`
 }
 
+// Deprecation describes the deprecation of a package, channel or bundle, as
+// declared by the olm.deprecations schema of a catalog
+type Deprecation struct {
+	// Message is the message describing the deprecation
+	Message string `json:"message"`
+}
+
 // Icon defines a base64 encoded icon and media type
 type Icon struct {
 	Data      []byte `json:"data,omitempty"`
@@ -90,7 +107,35 @@ type Icon struct {
 }
 
 // PackageStatus defines the observed state of Package
-type PackageStatus struct{}
+type PackageStatus struct {
+	// Channels contains the information derived from the entries of each of
+	// the package's channels.
+	Channels []ChannelStatus `json:"channels,omitempty"`
+
+	// PreferredCatalog is the name of the Catalog whose package of the same
+	// name is preferred over those of all other Catalogs, as determined by
+	// the priorities of the Catalogs.
+	PreferredCatalog string `json:"preferredCatalog,omitempty"`
+}
+
+// ChannelStatus contains the information derived from the entries of a single
+// channel of a package.
+type ChannelStatus struct {
+	// Name is the name of the channel.
+	Name string `json:"name"`
+
+	// Head is the name of the channel's head: the single entry that is
+	// neither replaced nor skipped by any other entry of the channel. Head is
+	// unset when the channel does not have exactly one such entry.
+	Head string `json:"head,omitempty"`
+
+	// LatestVersion is the highest version, as declared by the olm.package
+	// property of each bundle, of the bundles in the channel.
+	LatestVersion string `json:"latestVersion,omitempty"`
+
+	// BundleCount is the number of bundles in the channel.
+	BundleCount int `json:"bundleCount"`
+}
 
 func init() {
 	SchemeBuilder.Register(&Package{}, &PackageList{})
@@ -23,17 +23,34 @@ package v1alpha1
 
 import (
 	"encoding/json"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Authorization) DeepCopyInto(out *Authorization) {
+	*out = *in
+	out.Secret = in.Secret
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Authorization.
+func (in *Authorization) DeepCopy() *Authorization {
+	if in == nil {
+		return nil
+	}
+	out := new(Authorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BundleMetadata) DeepCopyInto(out *BundleMetadata) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleMetadata.
@@ -102,6 +119,16 @@ func (in *BundleMetadataSpec) DeepCopyInto(out *BundleMetadataSpec) {
 		*out = make([]RelatedImage, len(*in))
 		copy(*out, *in)
 	}
+	if in.BundleObjectRefs != nil {
+		in, out := &in.BundleObjectRefs, &out.BundleObjectRefs
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(Deprecation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleMetadataSpec.
@@ -117,6 +144,21 @@ func (in *BundleMetadataSpec) DeepCopy() *BundleMetadataSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BundleMetadataStatus) DeepCopyInto(out *BundleMetadataStatus) {
 	*out = *in
+	if in.ProvidedGVKs != nil {
+		in, out := &in.ProvidedGVKs, &out.ProvidedGVKs
+		*out = make([]GroupVersionKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredPackages != nil {
+		in, out := &in.RequiredPackages, &out.RequiredPackages
+		*out = make([]RequiredPackage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleMetadataStatus.
@@ -129,6 +171,105 @@ func (in *BundleMetadataStatus) DeepCopy() *BundleMetadataStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleObject) DeepCopyInto(out *BundleObject) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleObject.
+func (in *BundleObject) DeepCopy() *BundleObject {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BundleObject) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleObjectList) DeepCopyInto(out *BundleObjectList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BundleObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleObjectList.
+func (in *BundleObjectList) DeepCopy() *BundleObjectList {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleObjectList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BundleObjectList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleObjectSpec) DeepCopyInto(out *BundleObjectSpec) {
+	*out = *in
+	out.Catalog = in.Catalog
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleObjectSpec.
+func (in *BundleObjectSpec) DeepCopy() *BundleObjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleObjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BundleObjectsConfig) DeepCopyInto(out *BundleObjectsConfig) {
+	*out = *in
+	if in.MaxInlineSize != nil {
+		in, out := &in.MaxInlineSize, &out.MaxInlineSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BundleObjectsConfig.
+func (in *BundleObjectsConfig) DeepCopy() *BundleObjectsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BundleObjectsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Catalog) DeepCopyInto(out *Catalog) {
 	*out = *in
@@ -156,6 +297,33 @@ func (in *Catalog) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogFilter) DeepCopyInto(out *CatalogFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]PackageFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogFilter.
+func (in *CatalogFilter) DeepCopy() *CatalogFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CatalogList) DeepCopyInto(out *CatalogList) {
 	*out = *in
@@ -188,14 +356,108 @@ func (in *CatalogList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogMetadata) DeepCopyInto(out *CatalogMetadata) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogMetadata.
+func (in *CatalogMetadata) DeepCopy() *CatalogMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CatalogMetadata) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogMetadataList) DeepCopyInto(out *CatalogMetadataList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CatalogMetadata, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogMetadataList.
+func (in *CatalogMetadataList) DeepCopy() *CatalogMetadataList {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogMetadataList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CatalogMetadataList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CatalogMetadataSpec) DeepCopyInto(out *CatalogMetadataSpec) {
+	*out = *in
+	out.Catalog = in.Catalog
+	if in.Content != nil {
+		in, out := &in.Content, &out.Content
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogMetadataSpec.
+func (in *CatalogMetadataSpec) DeepCopy() *CatalogMetadataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CatalogMetadataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CatalogSource) DeepCopyInto(out *CatalogSource) {
 	*out = *in
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
 		*out = new(ImageSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitSource)
 		**out = **in
 	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPSource)
+		**out = **in
+	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]ConfigMapSource, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSource.
@@ -212,6 +474,16 @@ func (in *CatalogSource) DeepCopy() *CatalogSource {
 func (in *CatalogSpec) DeepCopyInto(out *CatalogSpec) {
 	*out = *in
 	in.Source.DeepCopyInto(&out.Source)
+	if in.BundleObjects != nil {
+		in, out := &in.BundleObjects, &out.BundleObjects
+		*out = new(BundleObjectsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = new(CatalogFilter)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CatalogSpec.
@@ -234,6 +506,15 @@ func (in *CatalogStatus) DeepCopyInto(out *CatalogStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PinnedImage != nil {
+		in, out := &in.PinnedImage, &out.PinnedImage
+		*out = new(PinnedImage)
+		**out = **in
+	}
+	if in.LastUnpackAttemptTime != nil {
+		in, out := &in.LastUnpackAttemptTime, &out.LastUnpackAttemptTime
+		*out = (*in).DeepCopy()
+	}
 	if in.ResolvedSource != nil {
 		in, out := &in.ResolvedSource, &out.ResolvedSource
 		*out = new(CatalogSource)
@@ -271,6 +552,115 @@ func (in *ChannelEntry) DeepCopy() *ChannelEntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChannelStatus) DeepCopyInto(out *ChannelStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChannelStatus.
+func (in *ChannelStatus) DeepCopy() *ChannelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChannelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapSource) DeepCopyInto(out *ConfigMapSource) {
+	*out = *in
+	out.ConfigMap = in.ConfigMap
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapSource.
+func (in *ConfigMapSource) DeepCopy() *ConfigMapSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Deprecation) DeepCopyInto(out *Deprecation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Deprecation.
+func (in *Deprecation) DeepCopy() *Deprecation {
+	if in == nil {
+		return nil
+	}
+	out := new(Deprecation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitRef) DeepCopyInto(out *GitRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitRef.
+func (in *GitRef) DeepCopy() *GitRef {
+	if in == nil {
+		return nil
+	}
+	out := new(GitRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSource) DeepCopyInto(out *GitSource) {
+	*out = *in
+	out.Ref = in.Ref
+	out.Auth = in.Auth
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSource.
+func (in *GitSource) DeepCopy() *GitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupVersionKind) DeepCopyInto(out *GroupVersionKind) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupVersionKind.
+func (in *GroupVersionKind) DeepCopy() *GroupVersionKind {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupVersionKind)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPSource) DeepCopyInto(out *HTTPSource) {
+	*out = *in
+	out.Auth = in.Auth
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPSource.
+func (in *HTTPSource) DeepCopy() *HTTPSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Icon) DeepCopyInto(out *Icon) {
 	*out = *in
@@ -294,6 +684,21 @@ func (in *Icon) DeepCopy() *Icon {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSource) DeepCopyInto(out *ImageSource) {
 	*out = *in
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(ImageVerification)
+		**out = **in
+	}
+	if in.UnpackTimeout != nil {
+		in, out := &in.UnpackTimeout, &out.UnpackTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageSource.
@@ -306,13 +711,29 @@ func (in *ImageSource) DeepCopy() *ImageSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageVerification) DeepCopyInto(out *ImageVerification) {
+	*out = *in
+	out.PublicKeys = in.PublicKeys
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageVerification.
+func (in *ImageVerification) DeepCopy() *ImageVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Package) DeepCopyInto(out *Package) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Package.
@@ -343,6 +764,11 @@ func (in *PackageChannel) DeepCopyInto(out *PackageChannel) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(Deprecation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageChannel.
@@ -355,6 +781,26 @@ func (in *PackageChannel) DeepCopy() *PackageChannel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PackageFilter) DeepCopyInto(out *PackageFilter) {
+	*out = *in
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageFilter.
+func (in *PackageFilter) DeepCopy() *PackageFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(PackageFilter)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageList) DeepCopyInto(out *PackageList) {
 	*out = *in
@@ -403,6 +849,11 @@ func (in *PackageSpec) DeepCopyInto(out *PackageSpec) {
 		*out = new(Icon)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Deprecation != nil {
+		in, out := &in.Deprecation, &out.Deprecation
+		*out = new(Deprecation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageSpec.
@@ -418,6 +869,11 @@ func (in *PackageSpec) DeepCopy() *PackageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PackageStatus) DeepCopyInto(out *PackageStatus) {
 	*out = *in
+	if in.Channels != nil {
+		in, out := &in.Channels, &out.Channels
+		*out = make([]ChannelStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PackageStatus.
@@ -430,6 +886,21 @@ func (in *PackageStatus) DeepCopy() *PackageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PinnedImage) DeepCopyInto(out *PinnedImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PinnedImage.
+func (in *PinnedImage) DeepCopy() *PinnedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(PinnedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Property) DeepCopyInto(out *Property) {
 	*out = *in
@@ -464,3 +935,18 @@ func (in *RelatedImage) DeepCopy() *RelatedImage {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequiredPackage) DeepCopyInto(out *RequiredPackage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequiredPackage.
+func (in *RequiredPackage) DeepCopy() *RequiredPackage {
+	if in == nil {
+		return nil
+	}
+	out := new(RequiredPackage)
+	in.DeepCopyInto(out)
+	return out
+}
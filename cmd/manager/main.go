@@ -17,9 +17,14 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -31,12 +36,15 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/operator-framework/catalogd/internal/source"
 	"github.com/operator-framework/catalogd/internal/version"
 	corecontrollers "github.com/operator-framework/catalogd/pkg/controllers/core"
 	"github.com/operator-framework/catalogd/pkg/features"
 	"github.com/operator-framework/catalogd/pkg/profile"
+	"github.com/operator-framework/catalogd/pkg/storage"
+	corewebhooks "github.com/operator-framework/catalogd/pkg/webhooks/core"
 	"github.com/spf13/pflag"
 
 	//+kubebuilder:scaffold:imports
@@ -64,6 +72,15 @@ func main() {
 		profiling            bool
 		catalogdVersion      bool
 		sysNs                string
+		cacheDir             string
+		registryMirrors      map[string]string
+		registryCAFile       string
+		enableWebhooks       bool
+		storageDir           string
+		catalogServerAddr    string
+		httpExternalAddr     string
+		unpackTimeout        time.Duration
+		maxUnpackBackoff     time.Duration
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -72,7 +89,15 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	// TODO: should we move the unpacker to some common place? Or... hear me out... should catalogd just be a rukpak provisioner?
 	flag.StringVar(&unpackImage, "unpack-image", "quay.io/operator-framework/rukpak:v0.12.0", "The unpack image to use when unpacking catalog images")
+	flag.DurationVar(&unpackTimeout, "unpack-timeout", 10*time.Minute, "The time that unpack pods, or direct unpacks, may take to unpack a catalog image before the unpack fails and is retried, unless the Catalog sets its own. Zero disables the timeout")
+	flag.DurationVar(&maxUnpackBackoff, "max-unpack-backoff", 5*time.Minute, "The maximum time to wait before retrying to unpack a Catalog whose previous attempts failed. Zero leaves retries to the controller's rate limiter")
 	flag.StringVar(&sysNs, "system-ns", "catalogd-system", "The namespace catalogd uses for internal state, configuration, and workloads")
+	flag.StringVar(&cacheDir, "cache-dir", "/var/cache/catalogd", "The directory in which directly unpacked catalog images are cached")
+	flag.StringVar(&registryCAFile, "registry-ca-file", "", "A PEM encoded CA bundle to trust, in addition to the system roots, when directly unpacking catalog images")
+	flag.StringVar(&storageDir, "catalogs-storage-dir", "/var/cache/catalogs", "The directory in which the contents of catalogs are stored to be served")
+	flag.StringVar(&catalogServerAddr, "catalogs-server-addr", ":8083", "The address the catalog content server binds to.")
+	flag.StringVar(&httpExternalAddr, "http-external-address", "http://catalogd-catalogserver.catalogd-system.svc", "The external address at which the catalog content server is reachable, used to build the content URLs published in Catalog statuses")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true, "enable the admission webhooks that validate catalogd resources")
 	flag.BoolVar(&profiling, "profiling", false, "enable profiling endpoints to allow for using pprof")
 	flag.BoolVar(&catalogdVersion, "version", false, "print the catalogd version and exit")
 	opts := zap.Options{
@@ -82,6 +107,7 @@ func main() {
 
 	// Combine both flagsets and parse them
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.StringToStringVar(&registryMirrors, "registry-mirrors", nil, "Mirrors to try before the source registry when directly unpacking catalog images, as comma separated registry=mirror pairs")
 	features.CatalogdFeatureGate.AddFlag(pflag.CommandLine)
 	pflag.Parse()
 
@@ -105,19 +131,50 @@ func main() {
 		os.Exit(1)
 	}
 
-	unpacker, err := source.NewDefaultUnpacker(mgr, sysNs, unpackImage)
+	unpacker, err := source.NewDefaultUnpacker(mgr, source.UnpackerOptions{
+		Namespace:         sysNs,
+		UnpackImage:       unpackImage,
+		UnpackTimeout:     unpackTimeout,
+		DirectImageUnpack: features.CatalogdFeatureGate.Enabled(features.DirectImageUnpack),
+		CacheDir:          cacheDir,
+		RegistryMirrors:   registryMirrors,
+		RegistryCAFile:    registryCAFile,
+	})
 	if err != nil {
 		setupLog.Error(err, "unable to create unpacker")
 		os.Exit(1)
 	}
 
+	baseURL, err := url.Parse(httpExternalAddr)
+	if err != nil {
+		setupLog.Error(err, "unable to parse http external address")
+		os.Exit(1)
+	}
+	localStorage := &storage.LocalDir{RootDir: storageDir, BaseURL: baseURL}
+	if err := mgr.Add(catalogServer(catalogServerAddr, localStorage)); err != nil {
+		setupLog.Error(err, "unable to set up catalog content server")
+		os.Exit(1)
+	}
+
 	if err = (&corecontrollers.CatalogReconciler{
-		Client:   mgr.GetClient(),
-		Unpacker: unpacker,
+		Client:           mgr.GetClient(),
+		Unpacker:         unpacker,
+		Recorder:         mgr.GetEventRecorderFor("catalogd-controller"),
+		Storage:          localStorage,
+		MaxUnpackBackoff: maxUnpackBackoff,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Catalog")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&corewebhooks.CatalogValidator{
+			Reader:          mgr.GetAPIReader(),
+			SystemNamespace: sysNs,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Catalog")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -143,3 +200,30 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// catalogServer returns a runnable that serves the stored contents of
+// catalogs at addr until the manager is stopped.
+func catalogServer(addr string, store storage.Instance) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		mux := http.NewServeMux()
+		mux.Handle(storage.CatalogsPath, store)
+		server := &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				setupLog.Error(err, "unable to shut down catalog content server")
+			}
+		}()
+		setupLog.Info("starting catalog content server", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+type ConfigMaps struct {
+	Reader             client.Reader
+	ConfigMapNamespace string
+}
+
+// Cleanup is a no-op, as the contents of config maps are read anew on every
+// unpack.
+func (o *ConfigMaps) Cleanup(_ context.Context, _ *catalogdv1alpha1.Catalog) error {
+	return nil
+}
+
+func (o *ConfigMaps) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (*Result, error) {
+	if catalog.Spec.Source.Type != catalogdv1alpha1.SourceTypeConfigMaps {
+		return nil, fmt.Errorf("catalog source type %q not supported", catalog.Spec.Source.Type)
+	}
+	if catalog.Spec.Source.ConfigMaps == nil {
+		return nil, fmt.Errorf("catalog source configmaps configuration is unset")
+	}
+
+	configMapSources := catalog.Spec.Source.ConfigMaps
+
+	catalogFS := fstest.MapFS{}
+	seenFilepaths := map[string]sets.Set[string]{}
+
+	for _, cmSource := range configMapSources {
+		cmName := cmSource.ConfigMap.Name
+		dir := filepath.ToSlash(filepath.Clean(cmSource.Path))
+		if dir == ".." || strings.HasPrefix(dir, "../") || path.IsAbs(dir) {
+			return nil, fmt.Errorf("configmap %q path %q must be within the catalog root", cmName, cmSource.Path)
+		}
+
+		var cm corev1.ConfigMap
+		if err := o.Reader.Get(ctx, client.ObjectKey{Name: cmName, Namespace: o.ConfigMapNamespace}, &cm); err != nil {
+			return nil, fmt.Errorf("get configmap %s/%s: %v", o.ConfigMapNamespace, cmName, err)
+		}
+
+		addToCatalog := func(configMapName, filename string, data []byte) {
+			filepath := path.Join(dir, filename)
+			if _, ok := seenFilepaths[filepath]; !ok {
+				seenFilepaths[filepath] = sets.New[string]()
+			}
+			seenFilepaths[filepath].Insert(configMapName)
+			catalogFS[filepath] = &fstest.MapFile{
+				Data: data,
+			}
+		}
+		for filename, data := range cm.Data {
+			addToCatalog(cmName, filename, []byte(data))
+		}
+		for filename, data := range cm.BinaryData {
+			addToCatalog(cmName, filename, data)
+		}
+	}
+
+	errs := []error{}
+	for _, filepath := range sets.List(sets.KeySet(seenFilepaths)) {
+		if cmNames := seenFilepaths[filepath]; len(cmNames) > 1 {
+			errs = append(errs, fmt.Errorf("duplicate path %q found in configmaps %v", filepath, sets.List(cmNames)))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	resolvedSource := &catalogdv1alpha1.CatalogSource{
+		Type:       catalogdv1alpha1.SourceTypeConfigMaps,
+		ConfigMaps: catalog.Spec.Source.DeepCopy().ConfigMaps,
+	}
+
+	message := fmt.Sprintf("successfully unpacked the catalog from %d configmaps", len(configMapSources))
+	return &Result{FS: catalogFS, ResolvedSource: resolvedSource, State: StateUnpacked, Message: message}, nil
+}
@@ -0,0 +1,100 @@
+package source_test
+
+import (
+	"context"
+	"io/fs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/catalogd/internal/source"
+)
+
+var _ = Describe("ConfigMaps Unpacker", func() {
+	const testNamespace = "catalogd-system"
+
+	var (
+		ctx        context.Context
+		configMaps []*corev1.ConfigMap
+		unpacker   *source.ConfigMaps
+		catalog    *catalogdv1alpha1.Catalog
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		configMaps = []*corev1.ConfigMap{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "packages", Namespace: testNamespace},
+				Data:       map[string]string{"package.yaml": "schema: olm.package\nname: foo\n"},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "bundles", Namespace: testNamespace},
+				BinaryData: map[string][]byte{"bundle.json": []byte(`{"schema":"olm.bundle"}`)},
+			},
+		}
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type: catalogdv1alpha1.SourceTypeConfigMaps,
+					ConfigMaps: []catalogdv1alpha1.ConfigMapSource{
+						{ConfigMap: corev1.LocalObjectReference{Name: "packages"}, Path: "foo"},
+						{ConfigMap: corev1.LocalObjectReference{Name: "bundles"}, Path: "foo/bundles"},
+					},
+				},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		builder := fake.NewClientBuilder().WithScheme(scheme.Scheme)
+		for _, cm := range configMaps {
+			builder = builder.WithObjects(cm)
+		}
+		unpacker = &source.ConfigMaps{Reader: builder.Build(), ConfigMapNamespace: testNamespace}
+	})
+
+	It("unpacks the contents of each configmap into its path", func() {
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(source.StateUnpacked))
+		Expect(result.ResolvedSource.Type).To(Equal(catalogdv1alpha1.SourceTypeConfigMaps))
+		Expect(result.ResolvedSource.ConfigMaps).To(Equal(catalog.Spec.Source.ConfigMaps))
+
+		data, err := fs.ReadFile(result.FS, "foo/package.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("schema: olm.package\nname: foo\n"))
+		data, err = fs.ReadFile(result.FS, "foo/bundles/bundle.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(`{"schema":"olm.bundle"}`))
+	})
+
+	It("rejects configmaps that place files at the same path", func() {
+		catalog.Spec.Source.ConfigMaps[1].Path = "foo"
+		configMaps[1].BinaryData = map[string][]byte{"package.yaml": []byte("conflict")}
+
+		unpacker = &source.ConfigMaps{
+			Reader:             fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(configMaps[0], configMaps[1]).Build(),
+			ConfigMapNamespace: testNamespace,
+		}
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring(`duplicate path "foo/package.yaml" found in configmaps [bundles packages]`)))
+	})
+
+	It("rejects paths outside of the catalog root", func() {
+		catalog.Spec.Source.ConfigMaps[0].Path = "../foo"
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("must be within the catalog root")))
+	})
+
+	It("returns an error when a configmap does not exist", func() {
+		catalog.Spec.Source.ConfigMaps[0].ConfigMap.Name = "missing"
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("get configmap catalogd-system/missing")))
+	})
+})
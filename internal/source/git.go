@@ -0,0 +1,218 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	sshgit "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+type Git struct {
+	client.Reader
+	SecretNamespace string
+}
+
+// Cleanup is a no-op, as repositories are cloned in memory on every unpack.
+func (r *Git) Cleanup(_ context.Context, _ *catalogdv1alpha1.Catalog) error {
+	return nil
+}
+
+func (r *Git) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (*Result, error) {
+	if catalog.Spec.Source.Type != catalogdv1alpha1.SourceTypeGit {
+		return nil, fmt.Errorf("catalog source type %q not supported", catalog.Spec.Source.Type)
+	}
+	if catalog.Spec.Source.Git == nil {
+		return nil, fmt.Errorf("catalog source git configuration is unset")
+	}
+	gitsource := catalog.Spec.Source.Git
+	if gitsource.Repository == "" {
+		// This should never happen because the validation happens in the catalog's
+		// CRD schema, but just in case.
+		return nil, fmt.Errorf("missing git source information: repository must be provided")
+	}
+
+	// Set options for clone
+	progress := bytes.Buffer{}
+	cloneOpts := git.CloneOptions{
+		URL:             gitsource.Repository,
+		Progress:        &progress,
+		Tags:            git.NoTags,
+		InsecureSkipTLS: gitsource.Auth.InsecureSkipVerify,
+	}
+
+	if gitsource.Auth.Secret.Name != "" {
+		auth, err := r.configAuth(ctx, gitsource)
+		if err != nil {
+			return nil, fmt.Errorf("configure git authentication: %v", err)
+		}
+		cloneOpts.Auth = auth
+	}
+
+	switch {
+	case gitsource.Ref.Branch != "":
+		cloneOpts.ReferenceName = plumbing.NewBranchReferenceName(gitsource.Ref.Branch)
+		cloneOpts.SingleBranch = true
+		cloneOpts.Depth = 1
+	case gitsource.Ref.Tag != "":
+		cloneOpts.ReferenceName = plumbing.NewTagReferenceName(gitsource.Ref.Tag)
+		cloneOpts.SingleBranch = true
+		cloneOpts.Depth = 1
+	case gitsource.Ref.Commit == "":
+		return nil, fmt.Errorf("missing git source information: one of branch, tag, or commit must be provided")
+	}
+
+	// Clone
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), memfs.New(), &cloneOpts)
+	if err != nil {
+		return nil, fmt.Errorf("clone repository %q: %v: %s", gitsource.Repository, err, progress.String())
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("load worktree for repository %q: %v", gitsource.Repository, err)
+	}
+
+	// Checkout commit
+	if gitsource.Ref.Commit != "" {
+		commitHash := plumbing.NewHash(gitsource.Ref.Commit)
+		if err := wt.Reset(&git.ResetOptions{
+			Commit: commitHash,
+			Mode:   git.HardReset,
+		}); err != nil {
+			return nil, fmt.Errorf("checkout commit %q for repository %q: %v", gitsource.Ref.Commit, gitsource.Repository, err)
+		}
+	}
+
+	// Subdirectory
+	directory := filepath.ToSlash(filepath.Clean(gitsource.Directory))
+	if directory == ".." || strings.HasPrefix(directory, "../") || path.IsAbs(directory) {
+		return nil, fmt.Errorf("get subdirectory %q for repository %q: directory must be within the repository", gitsource.Directory, gitsource.Repository)
+	}
+	catalogFS, err := billyToMapFS(wt.Filesystem, directory)
+	if err != nil {
+		return nil, fmt.Errorf("read subdirectory %q for repository %q: %v", gitsource.Directory, gitsource.Repository, err)
+	}
+
+	commitHash, err := repo.ResolveRevision("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("resolve commit hash for repository %q: %v", gitsource.Repository, err)
+	}
+
+	resolvedGit := gitsource.DeepCopy()
+	resolvedGit.Ref = catalogdv1alpha1.GitRef{
+		Commit: commitHash.String(),
+	}
+
+	resolvedSource := &catalogdv1alpha1.CatalogSource{
+		Type: catalogdv1alpha1.SourceTypeGit,
+		Git:  resolvedGit,
+	}
+
+	message := fmt.Sprintf("successfully unpacked git repository %q at commit %q", gitsource.Repository, commitHash.String())
+
+	return &Result{FS: catalogFS, ResolvedSource: resolvedSource, State: StateUnpacked, Message: message}, nil
+}
+
+func (r *Git) configAuth(ctx context.Context, gitsource *catalogdv1alpha1.GitSource) (transport.AuthMethod, error) {
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.SecretNamespace, Name: gitsource.Auth.Secret.Name}, secret); err != nil {
+		return nil, fmt.Errorf("get secret %s/%s: %v", r.SecretNamespace, gitsource.Auth.Secret.Name, err)
+	}
+
+	if strings.HasPrefix(gitsource.Repository, "http") {
+		if token := secret.Data["token"]; len(token) > 0 {
+			return &http.TokenAuth{Token: string(token)}, nil
+		}
+		return &http.BasicAuth{
+			Username: string(secret.Data["username"]),
+			Password: string(secret.Data["password"]),
+		}, nil
+	}
+
+	signer, err := ssh.ParsePrivateKey(secret.Data[corev1.SSHAuthPrivateKey])
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh private key: %v", err)
+	}
+	auth := &sshgit.PublicKeys{
+		User:   "git",
+		Signer: signer,
+	}
+	if gitsource.Auth.InsecureSkipVerify {
+		auth.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return auth, nil
+	}
+	knownHosts := secret.Data["ssh-knownhosts"]
+	if len(knownHosts) == 0 {
+		return nil, errors.New("ssh-knownhosts is required when insecureSkipVerify is false")
+	}
+	_, _, pubKey, _, _, err := ssh.ParseKnownHosts(knownHosts)
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh known hosts: %v", err)
+	}
+	auth.HostKeyCallback = ssh.FixedHostKey(pubKey)
+	return auth, nil
+}
+
+// billyToMapFS copies the directory tree rooted at root out of a billy
+// filesystem into an in-memory fs.FS.
+func billyToMapFS(bfs billy.Filesystem, root string) (fs.FS, error) {
+	mapFS := fstest.MapFS{}
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		infos, err := bfs.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			name := path.Join(dir, info.Name())
+			rel := name
+			if root != "." {
+				rel = strings.TrimPrefix(name, root+"/")
+			}
+			if info.IsDir() {
+				mapFS[rel] = &fstest.MapFile{Mode: fs.ModeDir | info.Mode().Perm(), ModTime: info.ModTime()}
+				if err := walk(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+			f, err := bfs.Open(name)
+			if err != nil {
+				return err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			mapFS[rel] = &fstest.MapFile{Data: data, Mode: info.Mode().Perm(), ModTime: info.ModTime()}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return mapFS, nil
+}
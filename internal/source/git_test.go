@@ -0,0 +1,117 @@
+package source_test
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/catalogd/internal/source"
+)
+
+var _ = Describe("Git Unpacker", func() {
+	var (
+		ctx      context.Context
+		repoDir  string
+		repo     *git.Repository
+		unpacker *source.Git
+		catalog  *catalogdv1alpha1.Catalog
+	)
+
+	// commitFile writes the given file into the test repository and commits
+	// it, returning the resulting commit hash.
+	commitFile := func(name, content string) plumbing.Hash {
+		path := filepath.Join(repoDir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		wt, err := repo.Worktree()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = wt.Add(name)
+		Expect(err).ToNot(HaveOccurred())
+		hash, err := wt.Commit("add "+name, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return hash
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		repoDir = GinkgoT().TempDir()
+
+		var err error
+		repo, err = git.PlainInit(repoDir, false)
+		Expect(err).ToNot(HaveOccurred())
+
+		unpacker = &source.Git{}
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type: catalogdv1alpha1.SourceTypeGit,
+					Git: &catalogdv1alpha1.GitSource{
+						Repository: repoDir,
+						Directory:  "catalog",
+						Ref:        catalogdv1alpha1.GitRef{Branch: "master"},
+					},
+				},
+			},
+		}
+	})
+
+	It("unpacks the catalog directory at the branch head", func() {
+		commitFile("README.md", "not part of the catalog")
+		head := commitFile("catalog/package.yaml", "schema: olm.package\nname: foo\n")
+
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(source.StateUnpacked))
+		Expect(result.ResolvedSource.Type).To(Equal(catalogdv1alpha1.SourceTypeGit))
+		Expect(result.ResolvedSource.Git.Ref).To(Equal(catalogdv1alpha1.GitRef{Commit: head.String()}))
+		Expect(result.ResolvedSource.Git.Directory).To(Equal("catalog"))
+
+		data, err := fs.ReadFile(result.FS, "package.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("schema: olm.package\nname: foo\n"))
+		_, err = fs.Stat(result.FS, "README.md")
+		Expect(err).To(MatchError(fs.ErrNotExist))
+	})
+
+	It("unpacks the catalog directory at a specific commit", func() {
+		first := commitFile("catalog/package.yaml", "schema: olm.package\nname: foo\n")
+		commitFile("catalog/package.yaml", "schema: olm.package\nname: bar\n")
+		catalog.Spec.Source.Git.Ref = catalogdv1alpha1.GitRef{Commit: first.String()}
+
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ResolvedSource.Git.Ref).To(Equal(catalogdv1alpha1.GitRef{Commit: first.String()}))
+
+		data, err := fs.ReadFile(result.FS, "package.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal("schema: olm.package\nname: foo\n"))
+	})
+
+	It("rejects directories outside of the repository", func() {
+		commitFile("catalog/package.yaml", "schema: olm.package\nname: foo\n")
+		catalog.Spec.Source.Git.Directory = "../outside"
+
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("directory must be within the repository")))
+	})
+
+	It("requires a ref", func() {
+		catalog.Spec.Source.Git.Ref = catalogdv1alpha1.GitRef{}
+
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("one of branch, tag, or commit must be provided")))
+	})
+})
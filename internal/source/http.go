@@ -0,0 +1,164 @@
+package source
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nlepage/go-tarfs"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// maxHTTPContentSize bounds the size of the catalog tarballs that are
+// downloaded from http sources, which are held in memory while unpacked.
+const maxHTTPContentSize = 256 << 20
+
+// maxHTTPUncompressedSize bounds the decompressed size of the catalog
+// tarballs that are downloaded from http sources. Without it, a small
+// gzipped tarball could decompress to an unbounded amount of memory.
+const maxHTTPUncompressedSize = 512 << 20
+
+type HTTP struct {
+	client.Reader
+	SecretNamespace string
+}
+
+// Cleanup is a no-op, as tarballs are downloaded anew on every unpack.
+func (h *HTTP) Cleanup(_ context.Context, _ *catalogdv1alpha1.Catalog) error {
+	return nil
+}
+
+func (h *HTTP) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (*Result, error) {
+	if catalog.Spec.Source.Type != catalogdv1alpha1.SourceTypeHTTP {
+		return nil, fmt.Errorf("catalog source type %q not supported", catalog.Spec.Source.Type)
+	}
+	if catalog.Spec.Source.HTTP == nil {
+		return nil, fmt.Errorf("catalog source http configuration is unset")
+	}
+	httpSource := catalog.Spec.Source.HTTP
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, httpSource.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request for %q: %v", httpSource.URL, err)
+	}
+	if httpSource.Auth.Secret.Name != "" {
+		if err := h.configAuth(ctx, httpSource, req); err != nil {
+			return nil, fmt.Errorf("configure http authentication: %v", err)
+		}
+	}
+
+	httpClient, err := httpClientFor(httpSource)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get %q: %v", httpSource.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %q: unexpected status %q", httpSource.URL, resp.Status)
+	}
+
+	if resp.ContentLength > maxHTTPContentSize {
+		return nil, fmt.Errorf("get %q: content length %d exceeds the maximum of %d bytes", httpSource.URL, resp.ContentLength, maxHTTPContentSize)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPContentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read response body from %q: %v", httpSource.URL, err)
+	}
+	if len(content) > maxHTTPContentSize {
+		return nil, fmt.Errorf("read response body from %q: content exceeds the maximum of %d bytes", httpSource.URL, maxHTTPContentSize)
+	}
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	if httpSource.SHA256 != "" && !strings.EqualFold(httpSource.SHA256, digest) {
+		return nil, fmt.Errorf("content digest mismatch for %q: expected sha256 %q, got %q", httpSource.URL, httpSource.SHA256, digest)
+	}
+
+	gzr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("read catalog content gzip: %v", err)
+	}
+	catalogFS, err := tarfs.New(&maxSizeReader{Reader: gzr, remaining: maxHTTPUncompressedSize})
+	if err != nil {
+		return nil, fmt.Errorf("read catalog content tar: %v", err)
+	}
+
+	resolvedHTTP := httpSource.DeepCopy()
+	resolvedHTTP.SHA256 = digest
+	resolvedSource := &catalogdv1alpha1.CatalogSource{
+		Type: catalogdv1alpha1.SourceTypeHTTP,
+		HTTP: resolvedHTTP,
+	}
+
+	message := fmt.Sprintf("successfully unpacked the catalog tarball %q with sha256 %q", httpSource.URL, digest)
+
+	return &Result{FS: catalogFS, ResolvedSource: resolvedSource, State: StateUnpacked, Message: message}, nil
+}
+
+// maxSizeReader reads from Reader and fails once more than remaining bytes
+// have been read, rather than silently truncating like io.LimitReader.
+type maxSizeReader struct {
+	io.Reader
+	remaining int64
+}
+
+func (r *maxSizeReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.Reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, fmt.Errorf("uncompressed content exceeds the maximum of %d bytes", maxHTTPUncompressedSize)
+	}
+	return n, err
+}
+
+func (h *HTTP) configAuth(ctx context.Context, httpSource *catalogdv1alpha1.HTTPSource, req *http.Request) error {
+	secret := &corev1.Secret{}
+	if err := h.Get(ctx, client.ObjectKey{Namespace: h.SecretNamespace, Name: httpSource.Auth.Secret.Name}, secret); err != nil {
+		return fmt.Errorf("get secret %s/%s: %v", h.SecretNamespace, httpSource.Auth.Secret.Name, err)
+	}
+	if token := secret.Data["token"]; len(token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+string(token))
+		return nil
+	}
+	req.SetBasicAuth(string(secret.Data["username"]), string(secret.Data["password"]))
+	return nil
+}
+
+// httpClientFor returns an HTTP client that trusts the system's certificate
+// authorities along with any provided in the source's certificate data.
+func httpClientFor(httpSource *catalogdv1alpha1.HTTPSource) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: httpSource.Auth.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if httpSource.CertificateData != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("load system certificate pool: %v", err)
+		}
+		if ok := pool.AppendCertsFromPEM([]byte(httpSource.CertificateData)); !ok {
+			return nil, errors.New("parse certificate data: no valid PEM certificates found")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
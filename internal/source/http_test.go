@@ -0,0 +1,244 @@
+package source_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/catalogd/internal/source"
+)
+
+var _ = Describe("HTTP Unpacker", func() {
+	const (
+		testNamespace  = "catalogd-system"
+		testSecretName = "catalog-auth"
+		testPackage    = "schema: olm.package\nname: foo\n"
+	)
+
+	var (
+		ctx      context.Context
+		tarball  []byte
+		digest   string
+		handler  http.HandlerFunc
+		server   *httptest.Server
+		unpacker *source.HTTP
+		catalog  *catalogdv1alpha1.Catalog
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		tarball = gzippedTarball(map[string]string{"catalog/package.yaml": testPackage})
+		sum := sha256.Sum256(tarball)
+		digest = hex.EncodeToString(sum[:])
+		handler = func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(tarball)
+		}
+		unpacker = &source.HTTP{SecretNamespace: testNamespace}
+	})
+
+	JustBeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { handler(w, r) }))
+		DeferCleanup(server.Close)
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type: catalogdv1alpha1.SourceTypeHTTP,
+					HTTP: &catalogdv1alpha1.HTTPSource{URL: server.URL + "/catalog.tar.gz"},
+				},
+			},
+		}
+	})
+
+	It("unpacks the tarball and pins the resolved source to its digest", func() {
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(source.StateUnpacked))
+		Expect(result.ResolvedSource.Type).To(Equal(catalogdv1alpha1.SourceTypeHTTP))
+		Expect(result.ResolvedSource.HTTP.URL).To(Equal(catalog.Spec.Source.HTTP.URL))
+		Expect(result.ResolvedSource.HTTP.SHA256).To(Equal(digest))
+
+		data, err := fs.ReadFile(result.FS, "catalog/package.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(testPackage))
+	})
+
+	It("accepts content matching the expected digest", func() {
+		catalog.Spec.Source.HTTP.SHA256 = digest
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("rejects content that does not match the expected digest", func() {
+		catalog.Spec.Source.HTTP.SHA256 = "0000000000000000000000000000000000000000000000000000000000000000"
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("content digest mismatch")))
+	})
+
+	When("the server responds with content that is too large", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Length", strconv.Itoa(1<<30))
+				w.WriteHeader(http.StatusOK)
+			}
+		})
+
+		It("returns an error without reading it", func() {
+			_, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).To(MatchError(ContainSubstring("exceeds the maximum")))
+		})
+	})
+
+	When("the tarball decompresses to more than the maximum size", func() {
+		BeforeEach(func() {
+			// A tarball of zeros compresses by a factor of roughly a thousand,
+			// so it is well within the maximum download size.
+			buf := &bytes.Buffer{}
+			gzw, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+			Expect(err).ToNot(HaveOccurred())
+			tw := tar.NewWriter(gzw)
+			const size = 600 << 20
+			Expect(tw.WriteHeader(&tar.Header{Name: "catalog/bomb", Mode: 0o644, Size: size, Typeflag: tar.TypeReg})).To(Succeed())
+			_, err = io.CopyN(tw, zeroReader{}, size)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tw.Close()).To(Succeed())
+			Expect(gzw.Close()).To(Succeed())
+			tarball = buf.Bytes()
+		})
+
+		It("returns an error", func() {
+			_, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).To(MatchError(ContainSubstring("uncompressed content exceeds the maximum")))
+		})
+	})
+
+	When("the server responds with an error", func() {
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		It("returns an error", func() {
+			_, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).To(MatchError(ContainSubstring("unexpected status")))
+		})
+	})
+
+	When("the server requires authentication", func() {
+		var secret *corev1.Secret
+
+		BeforeEach(func() {
+			handler = func(w http.ResponseWriter, r *http.Request) {
+				user, pass, ok := r.BasicAuth()
+				if r.Header.Get("Authorization") != "Bearer s3cr3t" && (!ok || user != "user" || pass != "pass") {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write(tarball)
+			}
+			secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: testSecretName, Namespace: testNamespace}}
+		})
+
+		JustBeforeEach(func() {
+			unpacker.Reader = fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+			catalog.Spec.Source.HTTP.Auth.Secret.Name = testSecretName
+		})
+
+		When("the secret contains basic auth credentials", func() {
+			BeforeEach(func() {
+				secret.Data = map[string][]byte{"username": []byte("user"), "password": []byte("pass")}
+			})
+
+			It("authenticates with them", func() {
+				_, err := unpacker.Unpack(ctx, catalog)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("the secret contains a bearer token", func() {
+			BeforeEach(func() {
+				secret.Data = map[string][]byte{"token": []byte("s3cr3t")}
+			})
+
+			It("authenticates with it", func() {
+				_, err := unpacker.Unpack(ctx, catalog)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("the secret contains the wrong credentials", func() {
+			BeforeEach(func() {
+				secret.Data = map[string][]byte{"token": []byte("wrong")}
+			})
+
+			It("returns an error", func() {
+				_, err := unpacker.Unpack(ctx, catalog)
+				Expect(err).To(MatchError(ContainSubstring("401 Unauthorized")))
+			})
+		})
+	})
+
+	When("the server uses a certificate signed by a private authority", func() {
+		var tlsServer *httptest.Server
+
+		JustBeforeEach(func() {
+			tlsServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { handler(w, r) }))
+			DeferCleanup(tlsServer.Close)
+			catalog.Spec.Source.HTTP.URL = tlsServer.URL + "/catalog.tar.gz"
+		})
+
+		It("fails without the authority's certificate", func() {
+			_, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).To(MatchError(ContainSubstring("certificate")))
+		})
+
+		It("succeeds when the authority's certificate is provided", func() {
+			catalog.Spec.Source.HTTP.CertificateData = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsServer.Certificate().Raw}))
+			_, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
+
+// gzippedTarball returns a gzipped tarball containing the given files.
+func gzippedTarball(files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gzw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gzw)
+	for name, content := range files {
+		Expect(tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gzw.Close()).To(Succeed())
+	return buf.Bytes()
+}
+
+// zeroReader is an endless source of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
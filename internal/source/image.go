@@ -9,7 +9,11 @@ import (
 	"io"
 	"io/fs"
 	"strings"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/nlepage/go-tarfs"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
@@ -20,26 +24,41 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
 )
 
 type Image struct {
 	Client       client.Client
+	APIReader    client.Reader
 	KubeClient   kubernetes.Interface
 	PodNamespace string
 	UnpackImage  string
+
+	// UnpackTimeout is the time that unpack pods may take to complete when a
+	// catalog does not set its own unpack timeout. Zero disables the timeout.
+	UnpackTimeout time.Duration
 }
 
 const imageCatalogUnpackContainerName = "catalog"
 
+// Cleanup is a no-op, as the unpack pod of the catalog is owned by it and
+// garbage collected along with it.
+func (i *Image) Cleanup(_ context.Context, _ *catalogdv1alpha1.Catalog) error {
+	return nil
+}
+
 func (i *Image) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (*Result, error) {
 	if catalog.Spec.Source.Type != catalogdv1alpha1.SourceTypeImage {
-		panic(fmt.Sprintf("source type %q is unable to handle specified catalog source type %q", catalogdv1alpha1.SourceTypeImage, catalog.Spec.Source.Type))
+		return nil, fmt.Errorf("catalog source type %q not supported", catalog.Spec.Source.Type)
 	}
 	if catalog.Spec.Source.Image == nil {
 		return nil, fmt.Errorf("catalog source image configuration is unset")
 	}
+	if err := checkDigestPolicy(catalog.Spec.Source.Image); err != nil {
+		return nil, err
+	}
 
 	pod := &corev1.Pod{}
 	op, err := i.ensureUnpackPod(ctx, catalog, pod)
@@ -51,13 +70,19 @@ func (i *Image) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (
 
 	switch phase := pod.Status.Phase; phase {
 	case corev1.PodPending:
-		return pendingImagePodResult(pod), nil
+		return i.checkDeadline(ctx, catalog, pod, pendingImagePodResult(pod))
 	case corev1.PodRunning:
-		return &Result{State: StateUnpacking}, nil
+		return i.checkDeadline(ctx, catalog, pod, &Result{State: StateUnpacking})
 	case corev1.PodFailed:
 		return nil, i.failedPodResult(ctx, pod)
 	case corev1.PodSucceeded:
-		return i.succeededPodResult(ctx, pod)
+		if i.imageChanged(ctx, catalog) {
+			if err := i.Client.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("delete outdated unpack pod: %v", err)
+			}
+			return &Result{State: StatePending, Message: "catalog image has a new digest; unpacking again"}, nil
+		}
+		return i.succeededPodResult(ctx, catalog, pod)
 	default:
 		return nil, i.handleUnexpectedPod(ctx, pod)
 	}
@@ -69,7 +94,7 @@ func (i *Image) ensureUnpackPod(ctx context.Context, catalog *catalogdv1alpha1.C
 		return controllerutil.OperationResultNone, err
 	}
 
-	podApplyConfig := i.getDesiredPodApplyConfig(catalog)
+	podApplyConfig := i.getDesiredPodApplyConfig(catalog, existingPod)
 	updatedPod, err := i.KubeClient.CoreV1().Pods(i.PodNamespace).Apply(ctx, podApplyConfig, metav1.ApplyOptions{Force: true, FieldManager: "catalogd-core"})
 	if err != nil {
 		if !apierrors.IsInvalid(err) {
@@ -98,7 +123,7 @@ func (i *Image) ensureUnpackPod(ctx context.Context, catalog *catalogdv1alpha1.C
 	return controllerutil.OperationResultUpdated, nil
 }
 
-func (i *Image) getDesiredPodApplyConfig(catalog *catalogdv1alpha1.Catalog) *applyconfigurationcorev1.PodApplyConfiguration {
+func (i *Image) getDesiredPodApplyConfig(catalog *catalogdv1alpha1.Catalog, existingPod *corev1.Pod) *applyconfigurationcorev1.PodApplyConfiguration {
 	// TODO: Address unpacker pod allowing root users for image sources
 	//
 	// In our current implementation, we are creating a pod that uses the image
@@ -117,6 +142,22 @@ func (i *Image) getDesiredPodApplyConfig(catalog *catalogdv1alpha1.Catalog) *app
 			WithDrop("ALL"),
 		)
 
+	catalogContainer := applyconfigurationcorev1.Container().
+		WithName(imageCatalogUnpackContainerName).
+		WithImage(podImage(catalog, existingPod)).
+		WithCommand("/util/bin/unpack", "--bundle-dir", "/configs").
+		WithVolumeMounts(applyconfigurationcorev1.VolumeMount().
+			WithName("util").
+			WithMountPath("/util/bin"),
+		).
+		WithSecurityContext(containerSecurityContext)
+
+	// Polled images are always pulled so that a new unpack pod never unpacks
+	// a stale, cached image that the tag no longer points to.
+	if catalog.Spec.Source.Image.PollInterval != nil {
+		catalogContainer = catalogContainer.WithImagePullPolicy(corev1.PullAlways)
+	}
+
 	podApply := applyconfigurationcorev1.Pod(catalog.Name, i.PodNamespace).
 		WithLabels(map[string]string{
 			"catalogd.operatorframework.io/owner-kind": catalog.Kind,
@@ -144,16 +185,7 @@ func (i *Image) getDesiredPodApplyConfig(catalog *catalogdv1alpha1.Catalog) *app
 				).
 				WithSecurityContext(containerSecurityContext),
 			).
-			WithContainers(applyconfigurationcorev1.Container().
-				WithName(imageCatalogUnpackContainerName).
-				WithImage(catalog.Spec.Source.Image.Ref).
-				WithCommand("/util/bin/unpack", "--bundle-dir", "/configs").
-				WithVolumeMounts(applyconfigurationcorev1.VolumeMount().
-					WithName("util").
-					WithMountPath("/util/bin"),
-				).
-				WithSecurityContext(containerSecurityContext),
-			).
+			WithContainers(catalogContainer).
 			WithVolumes(applyconfigurationcorev1.Volume().
 				WithName("util").
 				WithEmptyDir(applyconfigurationcorev1.EmptyDirVolumeSource()),
@@ -174,6 +206,34 @@ func (i *Image) getDesiredPodApplyConfig(catalog *catalogdv1alpha1.Catalog) *app
 	return podApply
 }
 
+// podImage returns the image that the unpack pod of the catalog runs. Catalogs
+// that report drift keep unpacking the digest they were last unpacked from, so
+// a recreated pod runs that digest rather than the one the image reference now
+// points to. An existing pod that already ran the pinned digest keeps its
+// image so that it is not updated, and unpacked again, for no reason.
+func podImage(catalog *catalogdv1alpha1.Catalog, existingPod *corev1.Pod) string {
+	imgSource := catalog.Spec.Source.Image
+	digest := pinnedDigest(catalog)
+	if imgSource.DigestPolicy != catalogdv1alpha1.DigestPolicyReportDrift || digest == "" {
+		return imgSource.Ref
+	}
+	for _, status := range existingPod.Status.ContainerStatuses {
+		if status.Name != imageCatalogUnpackContainerName || imageDigest(status.ImageID) != digest {
+			continue
+		}
+		for _, container := range existingPod.Spec.Containers {
+			if container.Name == imageCatalogUnpackContainerName {
+				return container.Image
+			}
+		}
+	}
+	imgRef, err := name.ParseReference(imgSource.Ref)
+	if err != nil {
+		return imgSource.Ref
+	}
+	return imgRef.Context().Digest(digest).String()
+}
+
 func unsetNonComparedPodFields(pods ...*corev1.Pod) {
 	for _, p := range pods {
 		p.APIVersion = ""
@@ -191,15 +251,19 @@ func (i *Image) failedPodResult(ctx context.Context, pod *corev1.Pod) error {
 	return fmt.Errorf("unpack failed: %v", string(logs))
 }
 
-func (i *Image) succeededPodResult(ctx context.Context, pod *corev1.Pod) (*Result, error) {
-	catalogFS, err := i.getCatalogContents(ctx, pod)
+func (i *Image) succeededPodResult(ctx context.Context, catalog *catalogdv1alpha1.Catalog, pod *corev1.Pod) (*Result, error) {
+	digest, err := i.getCatalogImageDigest(pod)
 	if err != nil {
-		return nil, fmt.Errorf("get catalog contents: %v", err)
+		return nil, fmt.Errorf("get catalog image digest: %v", err)
 	}
 
-	digest, err := i.getCatalogImageDigest(pod)
+	if err := i.verify(ctx, catalog, digest); err != nil {
+		return nil, err
+	}
+
+	catalogFS, err := i.getCatalogContents(ctx, pod)
 	if err != nil {
-		return nil, fmt.Errorf("get catalog image digest: %v", err)
+		return nil, fmt.Errorf("get catalog contents: %v", err)
 	}
 
 	resolvedSource := &catalogdv1alpha1.CatalogSource{
@@ -209,7 +273,15 @@ func (i *Image) succeededPodResult(ctx context.Context, pod *corev1.Pod) (*Resul
 
 	message := fmt.Sprintf("successfully unpacked the catalog image %q", digest)
 
-	return &Result{FS: catalogFS, ResolvedSource: resolvedSource, State: StateUnpacked, Message: message}, nil
+	result := &Result{FS: catalogFS, ResolvedSource: resolvedSource, State: StateUnpacked, Message: message, StartTime: pod.CreationTimestamp.Time}
+	if imgSource := catalog.Spec.Source.Image; imgSource.DigestPolicy == catalogdv1alpha1.DigestPolicyReportDrift {
+		latest, err := resolveImageDigest(ctx, i.APIReader, i.PodNamespace, imgSource.PullSecret, imgSource.Ref)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "unable to check catalog image for drift", "ref", imgSource.Ref)
+		}
+		result.LatestDigest = latest
+	}
+	return result, nil
 }
 
 func (i *Image) getCatalogContents(ctx context.Context, pod *corev1.Pod) (fs.FS, error) {
@@ -254,6 +326,78 @@ func (i *Image) getPodLogs(ctx context.Context, pod *corev1.Pod) ([]byte, error)
 	return buf.Bytes(), nil
 }
 
+// imageChanged reports whether the image reference of a polled catalog now
+// resolves to a different digest than the one recorded in the catalog's
+// resolved source. Failures to reach the registry are logged and treated as
+// "unchanged" so that a registry outage does not disrupt already unpacked
+// content. Catalogs whose digest policy is ReportDrift never follow their
+// image reference to a new digest.
+func (i *Image) imageChanged(ctx context.Context, catalog *catalogdv1alpha1.Catalog) bool {
+	imgSource := catalog.Spec.Source.Image
+	resolved := catalog.Status.ResolvedSource
+	if imgSource.PollInterval == nil || imgSource.DigestPolicy == catalogdv1alpha1.DigestPolicyReportDrift || resolved == nil || resolved.Image == nil {
+		return false
+	}
+	current := imageDigest(resolved.Image.Ref)
+	if current == "" {
+		return false
+	}
+	latest, err := resolveImageDigest(ctx, i.APIReader, i.PodNamespace, imgSource.PullSecret, imgSource.Ref)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to poll catalog image", "ref", imgSource.Ref)
+		return false
+	}
+	return latest != current
+}
+
+// verify enforces the verification policy of the catalog's image source on
+// the image that the unpack pod ran, as identified by its image ID, before its
+// contents are trusted.
+func (i *Image) verify(ctx context.Context, catalog *catalogdv1alpha1.Catalog, imageID string) error {
+	imgSource := catalog.Spec.Source.Image
+	if imgSource.Verification == nil {
+		return nil
+	}
+	digest := imageDigest(imageID)
+	if digest == "" {
+		return fmt.Errorf("catalog image ID %q does not contain a digest to verify", imageID)
+	}
+	imgRef, err := name.ParseReference(imgSource.Ref)
+	if err != nil {
+		return fmt.Errorf("parse image reference %q: %v", imgSource.Ref, err)
+	}
+	keychain, err := pullSecretKeychain(ctx, i.APIReader, i.PodNamespace, imgSource.PullSecret)
+	if err != nil {
+		return err
+	}
+	return verifyImage(ctx, i.APIReader, i.PodNamespace, imgSource.Verification, imgRef.Context().Digest(digest), func(ref name.Reference) (ggcrv1.Image, error) {
+		return remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	})
+}
+
+// checkDeadline fails the unpack once the unpack pod has not completed within
+// the unpack timeout of the catalog, deleting the pod so that the unpack
+// starts over when it is retried. Until then, the deadline of the pod is
+// recorded in the result.
+func (i *Image) checkDeadline(ctx context.Context, catalog *catalogdv1alpha1.Catalog, pod *corev1.Pod, result *Result) (*Result, error) {
+	timeout := i.UnpackTimeout
+	if catalog.Spec.Source.Image.UnpackTimeout != nil {
+		timeout = catalog.Spec.Source.Image.UnpackTimeout.Duration
+	}
+	if timeout <= 0 {
+		return result, nil
+	}
+	deadline := pod.CreationTimestamp.Add(timeout)
+	if time.Now().Before(deadline) {
+		result.Deadline = deadline
+		return result, nil
+	}
+	if err := i.Client.Delete(ctx, pod); client.IgnoreNotFound(err) != nil {
+		return nil, fmt.Errorf("delete timed out unpack pod: %v", err)
+	}
+	return nil, &UnpackTimeoutError{Timeout: timeout, Message: result.Message}
+}
+
 func (i *Image) handleUnexpectedPod(ctx context.Context, pod *corev1.Pod) error {
 	_ = i.Client.Delete(ctx, pod)
 	return fmt.Errorf("unexpected pod phase: %v", pod.Status.Phase)
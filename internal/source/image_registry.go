@@ -0,0 +1,359 @@
+package source
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// configDirLabel is the image label that catalog images use to declare the
+// directory in which their file-based catalog lives.
+const configDirLabel = "operators.operatorframework.io.index.configs.v1"
+
+// defaultConfigDir is the directory used when a catalog image does not
+// declare one with the configDirLabel.
+const defaultConfigDir = "/configs"
+
+// ImageRegistry is an image source that pulls catalog images directly from
+// their registry and unpacks them within the catalogd process, rather than
+// running an unpack pod. Unpacked contents are cached on local disk, keyed
+// by the image digest, so that only new digests are pulled.
+type ImageRegistry struct {
+	Reader          client.Reader
+	SecretNamespace string
+
+	// CacheDir is the directory in which unpacked catalog contents are stored.
+	CacheDir string
+
+	// Mirrors maps registry hosts to mirror hosts that should be tried first
+	// when pulling images from that registry.
+	Mirrors map[string]string
+
+	// Transport is the HTTP transport used to talk to registries. If nil,
+	// remote.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// UnpackTimeout is the time that pulling and unpacking a catalog image
+	// may take when the catalog does not set its own unpack timeout. Zero
+	// disables the timeout.
+	UnpackTimeout time.Duration
+}
+
+// Unpack pulls and unpacks the catalog image within the unpack timeout of the
+// catalog, so that an unresponsive registry does not hold up the reconciles
+// of other catalogs indefinitely.
+func (i *ImageRegistry) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (*Result, error) {
+	timeout := i.UnpackTimeout
+	if imgSource := catalog.Spec.Source.Image; imgSource != nil && imgSource.UnpackTimeout != nil {
+		timeout = imgSource.UnpackTimeout.Duration
+	}
+	if timeout <= 0 {
+		return i.unpack(ctx, catalog)
+	}
+	unpackCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := i.unpack(unpackCtx, catalog)
+	if err != nil && errors.Is(unpackCtx.Err(), context.DeadlineExceeded) {
+		return nil, &UnpackTimeoutError{Timeout: timeout, Message: err.Error()}
+	}
+	return result, err
+}
+
+// Cleanup removes the cached contents of the catalog.
+func (i *ImageRegistry) Cleanup(_ context.Context, catalog *catalogdv1alpha1.Catalog) error {
+	if err := os.RemoveAll(filepath.Join(i.CacheDir, catalog.Name)); err != nil {
+		return fmt.Errorf("remove cached catalog contents: %v", err)
+	}
+	return nil
+}
+
+func (i *ImageRegistry) unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog) (*Result, error) {
+	if catalog.Spec.Source.Type != catalogdv1alpha1.SourceTypeImage {
+		return nil, fmt.Errorf("catalog source type %q not supported", catalog.Spec.Source.Type)
+	}
+	if catalog.Spec.Source.Image == nil {
+		return nil, fmt.Errorf("catalog source image configuration is unset")
+	}
+	imgSource := catalog.Spec.Source.Image
+	if err := checkDigestPolicy(imgSource); err != nil {
+		return nil, err
+	}
+
+	imgRef, err := name.ParseReference(imgSource.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("parse image reference %q: %v", imgSource.Ref, err)
+	}
+	keychain, err := pullSecretKeychain(ctx, i.Reader, i.SecretNamespace, imgSource.PullSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Catalogs that report drift keep unpacking the digest they were last
+	// unpacked from, rather than the one their reference now points to.
+	fetchRef := imgRef
+	reportDrift := imgSource.DigestPolicy == catalogdv1alpha1.DigestPolicyReportDrift
+	pinned := reportDrift && pinnedDigest(catalog) != ""
+	if pinned {
+		fetchRef = imgRef.Context().Digest(pinnedDigest(catalog))
+	}
+
+	opts := []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain), remote.WithTransport(i.transport())}
+	desc, err := i.get(ctx, fetchRef, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("fetch image %q: %v", fetchRef, err)
+	}
+	resolvedRef := imgRef.Context().Digest(desc.Digest.String())
+
+	if err := verifyImage(ctx, i.Reader, i.SecretNamespace, imgSource.Verification, resolvedRef, func(ref name.Reference) (v1.Image, error) {
+		sigDesc, err := i.get(ctx, ref, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return sigDesc.Image()
+	}); err != nil {
+		return nil, err
+	}
+
+	unpackPath := filepath.Join(i.CacheDir, catalog.Name, desc.Digest.Hex)
+	if _, err := os.Stat(unpackPath); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("stat cached catalog contents: %v", err)
+		}
+		img, err := desc.Image()
+		if err != nil {
+			return nil, fmt.Errorf("get image %q: %v", resolvedRef, err)
+		}
+		if err := i.unpackImage(img, unpackPath); err != nil {
+			return nil, fmt.Errorf("unpack image %q: %v", resolvedRef, err)
+		}
+	}
+	if err := i.pruneCache(catalog.Name, desc.Digest.Hex); err != nil {
+		log.FromContext(ctx).Error(err, "unable to prune cached catalog contents", "catalog", catalog.Name)
+	}
+
+	resolvedSource := &catalogdv1alpha1.CatalogSource{
+		Type:  catalogdv1alpha1.SourceTypeImage,
+		Image: &catalogdv1alpha1.ImageSource{Ref: resolvedRef.String()},
+	}
+
+	message := fmt.Sprintf("successfully unpacked the catalog image %q", resolvedRef)
+
+	result := &Result{FS: os.DirFS(unpackPath), ResolvedSource: resolvedSource, State: StateUnpacked, Message: message}
+	if reportDrift {
+		result.LatestDigest = desc.Digest.String()
+		if pinned {
+			latest, err := i.get(ctx, imgRef, opts...)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "unable to check catalog image for drift", "ref", imgSource.Ref)
+				result.LatestDigest = ""
+			} else {
+				result.LatestDigest = latest.Digest.String()
+			}
+		}
+	}
+	return result, nil
+}
+
+// pinnedDigest returns the digest that the catalog was last unpacked from, as
+// long as its image reference has not changed since.
+func pinnedDigest(catalog *catalogdv1alpha1.Catalog) string {
+	pinned := catalog.Status.PinnedImage
+	if pinned == nil || catalog.Spec.Source.Image == nil || pinned.Ref != catalog.Spec.Source.Image.Ref {
+		return ""
+	}
+	return pinned.Digest
+}
+
+// get fetches the descriptor of the referenced image, trying the configured
+// mirror of the image's registry before the registry itself.
+func (i *ImageRegistry) get(ctx context.Context, ref name.Reference, opts ...remote.Option) (*remote.Descriptor, error) {
+	opts = append(opts, remote.WithPlatform(v1.Platform{OS: "linux", Architecture: runtime.GOARCH}))
+	if mirror, ok := i.Mirrors[ref.Context().RegistryStr()]; ok {
+		mirrorRef, err := mirrorReference(ref, mirror)
+		if err != nil {
+			return nil, err
+		}
+		desc, err := remote.Get(mirrorRef, opts...)
+		if err == nil {
+			return desc, nil
+		}
+		log.FromContext(ctx).Info("unable to fetch image from mirror, falling back to source registry", "ref", ref.String(), "mirror", mirror, "error", err.Error())
+	}
+	return remote.Get(ref, opts...)
+}
+
+// registryTransport returns a transport for talking to registries that
+// trusts the system roots and, if caFile is set, the CAs contained in it.
+func registryTransport(caFile string) (http.RoundTripper, error) {
+	if caFile == "" {
+		return remote.DefaultTransport, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read registry CA file %q: %v", caFile, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("registry CA file %q contains no valid certificates", caFile)
+	}
+	transport := remote.DefaultTransport.(*http.Transport).Clone()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = pool
+	return transport, nil
+}
+
+func (i *ImageRegistry) transport() http.RoundTripper {
+	if i.Transport == nil {
+		return remote.DefaultTransport
+	}
+	return i.Transport
+}
+
+// unpackImage writes the catalog contents of the image into dest. The
+// contents are first written to a temporary directory that is renamed into
+// place once complete, so that dest only ever contains a full catalog.
+func (i *ImageRegistry) unpackImage(img v1.Image, dest string) error {
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("get image config: %v", err)
+	}
+	configDir := defaultConfigDir
+	if dir, ok := cfg.Config.Labels[configDirLabel]; ok {
+		configDir = dir
+	}
+	configDir = path.Clean(strings.TrimPrefix(configDir, "/"))
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dest), ".unpack-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	found := false
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read image filesystem: %v", err)
+		}
+
+		rel, ok := relativeTo(path.Clean(strings.TrimPrefix(hdr.Name, "/")), configDir)
+		if !ok {
+			continue
+		}
+		target := filepath.Join(tmpDir, filepath.FromSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr); err != nil {
+				return err
+			}
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("no catalog contents found in image directory %q", "/"+configDir)
+	}
+	return os.Rename(tmpDir, dest)
+}
+
+// pruneCache removes all cached contents of the named catalog other than the
+// contents of the given digest.
+func (i *ImageRegistry) pruneCache(catalogName, keepDigest string) error {
+	catalogDir := filepath.Join(i.CacheDir, catalogName)
+	entries, err := os.ReadDir(catalogDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Name() == keepDigest {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(catalogDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mirrorReference returns a copy of ref that points at the same repository
+// and tag or digest in the mirror registry.
+func mirrorReference(ref name.Reference, mirror string) (name.Reference, error) {
+	sep := ":"
+	if _, ok := ref.(name.Digest); ok {
+		sep = "@"
+	}
+	mirrorRef, err := name.ParseReference(fmt.Sprintf("%s/%s%s%s", mirror, ref.Context().RepositoryStr(), sep, ref.Identifier()))
+	if err != nil {
+		return nil, fmt.Errorf("parse mirror reference for %q: %v", ref, err)
+	}
+	return mirrorRef, nil
+}
+
+// relativeTo returns p relative to dir if p is dir or is within it.
+func relativeTo(p, dir string) (string, bool) {
+	if p == dir {
+		return ".", true
+	}
+	if dir == "." {
+		return p, p != ".." && !strings.HasPrefix(p, "../")
+	}
+	if strings.HasPrefix(p, dir+"/") {
+		return strings.TrimPrefix(p, dir+"/"), true
+	}
+	return "", false
+}
+
+func writeFile(name string, r io.Reader) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
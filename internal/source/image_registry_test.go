@@ -0,0 +1,213 @@
+package source_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/catalogd/internal/source"
+)
+
+var _ = Describe("ImageRegistry Unpacker", func() {
+	const testPackage = "schema: olm.package\nname: foo\n"
+
+	var (
+		ctx      context.Context
+		host     string
+		unpacker *source.ImageRegistry
+		catalog  *catalogdv1alpha1.Catalog
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		host = newTestRegistry()
+		unpacker = &source.ImageRegistry{CacheDir: GinkgoT().TempDir()}
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type:  catalogdv1alpha1.SourceTypeImage,
+					Image: &catalogdv1alpha1.ImageSource{Ref: host + "/catalogs/test:latest"},
+				},
+			},
+		}
+	})
+
+	It("unpacks the default config directory and pins the resolved source to a digest", func() {
+		digest := pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{
+			"configs/foo/package.yaml": testPackage,
+			"etc/passwd":               "root:x:0:0::/root:/bin/sh\n",
+		})
+
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(source.StateUnpacked))
+		Expect(result.ResolvedSource.Image.Ref).To(Equal(host + "/catalogs/test@" + digest.String()))
+
+		data, err := fs.ReadFile(result.FS, "foo/package.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(testPackage))
+		_, err = fs.Stat(result.FS, "etc/passwd")
+		Expect(err).To(MatchError(fs.ErrNotExist))
+	})
+
+	It("unpacks the config directory declared by the image label", func() {
+		pushCatalogImage(host+"/catalogs/test:latest", map[string]string{
+			"operators.operatorframework.io.index.configs.v1": "/catalog",
+		}, map[string]string{"catalog/foo/package.yaml": testPackage})
+
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fs.Stat(result.FS, "foo/package.yaml")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns an error when the image has no catalog contents", func() {
+		pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"etc/passwd": "root:x:0:0::/root:/bin/sh\n"})
+
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("no catalog contents found")))
+	})
+
+	It("prunes the cached contents of previous digests", func() {
+		old := pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"configs/foo/package.yaml": testPackage})
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+
+		current := pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"configs/bar/package.yaml": testPackage})
+		_, err = unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+
+		entries, err := os.ReadDir(filepath.Join(unpacker.CacheDir, catalog.Name))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name()).To(Equal(current.Hex))
+		Expect(entries[0].Name()).ToNot(Equal(old.Hex))
+	})
+
+	It("rejects a tag reference when the digest policy requires a digest", func() {
+		catalog.Spec.Source.Image.DigestPolicy = catalogdv1alpha1.DigestPolicyRequire
+
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).To(MatchError(ContainSubstring("requires a digest reference")))
+	})
+
+	It("fails with an unpack timeout error when the registry does not respond in time", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		DeferCleanup(server.Close)
+		catalog.Spec.Source.Image.Ref = strings.TrimPrefix(server.URL, "http://") + "/catalogs/test:latest"
+		catalog.Spec.Source.Image.UnpackTimeout = &metav1.Duration{Duration: 100 * time.Millisecond}
+
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(source.IsUnpackTimeoutError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("catalog was not unpacked within 100ms")))
+	})
+
+	It("removes the cached contents of the catalog on cleanup", func() {
+		pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"configs/foo/package.yaml": testPackage})
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(filepath.Join(unpacker.CacheDir, catalog.Name)).To(BeADirectory())
+
+		Expect(unpacker.Cleanup(ctx, catalog)).To(Succeed())
+		_, err = os.Stat(filepath.Join(unpacker.CacheDir, catalog.Name))
+		Expect(errors.Is(err, fs.ErrNotExist)).To(BeTrue())
+	})
+
+	When("the digest policy reports drift", func() {
+		BeforeEach(func() {
+			catalog.Spec.Source.Image.DigestPolicy = catalogdv1alpha1.DigestPolicyReportDrift
+		})
+
+		It("keeps unpacking the last unpacked digest and reports the digest the tag points to", func() {
+			unpacked := pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"configs/foo/package.yaml": testPackage})
+			result, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.LatestDigest).To(Equal(unpacked.String()))
+			catalog.Status.PinnedImage = &catalogdv1alpha1.PinnedImage{Ref: catalog.Spec.Source.Image.Ref, Digest: unpacked.String()}
+
+			By("retrying after a failed attempt cleared the resolved source")
+			catalog.Status.Phase = catalogdv1alpha1.PhaseFailing
+			catalog.Status.ResolvedSource = nil
+			latest := pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"configs/bar/package.yaml": testPackage})
+			result, err = unpacker.Unpack(ctx, catalog)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ResolvedSource.Image.Ref).To(Equal(host + "/catalogs/test@" + unpacked.String()))
+			Expect(result.LatestDigest).To(Equal(latest.String()))
+			_, err = fs.Stat(result.FS, "foo/package.yaml")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	When("a mirror is configured for the registry", func() {
+		var mirror string
+
+		BeforeEach(func() {
+			mirror = newTestRegistry()
+			unpacker.Mirrors = map[string]string{host: mirror}
+		})
+
+		It("pulls the image from the mirror", func() {
+			digest := pushCatalogImage(mirror+"/catalogs/test:latest", nil, map[string]string{"configs/foo/package.yaml": testPackage})
+
+			result, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.ResolvedSource.Image.Ref).To(Equal(host + "/catalogs/test@" + digest.String()))
+		})
+
+		It("falls back to the source registry when the mirror does not have the image", func() {
+			pushCatalogImage(host+"/catalogs/test:latest", nil, map[string]string{"configs/foo/package.yaml": testPackage})
+
+			_, err := unpacker.Unpack(ctx, catalog)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+})
+
+// newTestRegistry starts an in-memory registry and returns its host.
+func newTestRegistry() string {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	DeferCleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// pushCatalogImage pushes a single layer image containing the given files
+// and labels to ref and returns its digest.
+func pushCatalogImage(ref string, labels map[string]string, files map[string]string) v1.Hash {
+	layer, err := tarball.LayerFromReader(strings.NewReader(string(gzippedTarball(files))))
+	Expect(err).ToNot(HaveOccurred())
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	Expect(err).ToNot(HaveOccurred())
+	img, err = mutate.Config(img, v1.Config{Labels: labels})
+	Expect(err).ToNot(HaveOccurred())
+
+	tag, err := name.ParseReference(ref)
+	Expect(err).ToNot(HaveOccurred())
+	Expect(remote.Write(tag, img)).To(Succeed())
+
+	digest, err := img.Digest()
+	Expect(err).ToNot(HaveOccurred())
+	return digest
+}
@@ -0,0 +1,253 @@
+package source
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	ggcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+var _ = Describe("Image unpack pod", func() {
+	var (
+		unpacker *Image
+		catalog  *catalogdv1alpha1.Catalog
+		digest   string
+	)
+
+	BeforeEach(func() {
+		unpacker = &Image{PodNamespace: "catalogd-system", UnpackImage: "quay.io/operator-framework/rukpak:latest"}
+		digest = "sha256:" + strings.Repeat("a", 64)
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type: catalogdv1alpha1.SourceTypeImage,
+					Image: &catalogdv1alpha1.ImageSource{
+						Ref:          "quay.io/test/catalog:latest",
+						DigestPolicy: catalogdv1alpha1.DigestPolicyReportDrift,
+					},
+				},
+			},
+			Status: catalogdv1alpha1.CatalogStatus{
+				Phase: catalogdv1alpha1.PhaseUnpacked,
+				ResolvedSource: &catalogdv1alpha1.CatalogSource{
+					Type:  catalogdv1alpha1.SourceTypeImage,
+					Image: &catalogdv1alpha1.ImageSource{Ref: "quay.io/test/catalog@" + digest},
+				},
+				PinnedImage: &catalogdv1alpha1.PinnedImage{Ref: "quay.io/test/catalog:latest", Digest: digest},
+			},
+		}
+	})
+
+	// catalogImage returns the image of the catalog container of the unpack
+	// pod that is applied for the catalog when existingPod already exists.
+	catalogImage := func(existingPod *corev1.Pod) string {
+		podApply := unpacker.getDesiredPodApplyConfig(catalog, existingPod)
+		for _, container := range podApply.Spec.Containers {
+			if *container.Name == imageCatalogUnpackContainerName {
+				return *container.Image
+			}
+		}
+		Fail("unpack pod has no catalog container")
+		return ""
+	}
+
+	It("runs the image reference of a catalog that has not been unpacked", func() {
+		catalog.Status = catalogdv1alpha1.CatalogStatus{}
+		Expect(catalogImage(&corev1.Pod{})).To(Equal("quay.io/test/catalog:latest"))
+	})
+
+	It("recreates the pod of a catalog that reports drift with its unpacked digest", func() {
+		Expect(catalogImage(&corev1.Pod{})).To(Equal("quay.io/test/catalog@" + digest))
+	})
+
+	It("keeps the image of an existing pod that ran the unpacked digest", func() {
+		existingPod := &corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: imageCatalogUnpackContainerName, Image: "quay.io/test/catalog:latest"}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    imageCatalogUnpackContainerName,
+				ImageID: "quay.io/test/catalog@" + digest,
+			}}},
+		}
+		Expect(catalogImage(existingPod)).To(Equal("quay.io/test/catalog:latest"))
+	})
+
+	It("keeps running the unpacked digest when a pending pod is reconciled again", func() {
+		catalog.Status.Phase = catalogdv1alpha1.PhasePending
+		catalog.Status.ResolvedSource = nil
+		pendingPod := &corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: imageCatalogUnpackContainerName, Image: "quay.io/test/catalog@" + digest}}},
+		}
+		Expect(catalogImage(pendingPod)).To(Equal("quay.io/test/catalog@" + digest))
+	})
+
+	It("retries a failed unpack with the unpacked digest", func() {
+		catalog.Status.Phase = catalogdv1alpha1.PhaseFailing
+		catalog.Status.ResolvedSource = nil
+		Expect(catalogImage(&corev1.Pod{})).To(Equal("quay.io/test/catalog@" + digest))
+	})
+
+	It("runs the image reference once it is updated", func() {
+		catalog.Spec.Source.Image.Ref = "quay.io/test/catalog:v2"
+		Expect(catalogImage(&corev1.Pod{})).To(Equal("quay.io/test/catalog:v2"))
+	})
+
+	It("runs the image reference of a catalog that does not report drift", func() {
+		catalog.Spec.Source.Image.DigestPolicy = ""
+		Expect(catalogImage(&corev1.Pod{})).To(Equal("quay.io/test/catalog:latest"))
+	})
+})
+
+var _ = Describe("Image unpack pod verification", func() {
+	const systemNamespace = "catalogd-system"
+
+	var (
+		ctx        context.Context
+		repo       string
+		digest     ggcrv1.Hash
+		trustedKey *ecdsa.PrivateKey
+		kubeClient *kubefake.Clientset
+		unpacker   *Image
+		catalog    *catalogdv1alpha1.Catalog
+		pod        *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+		DeferCleanup(server.Close)
+		repo = strings.TrimPrefix(server.URL, "http://") + "/catalogs/test"
+
+		img, err := random.Image(64, 1)
+		Expect(err).ToNot(HaveOccurred())
+		tag, err := name.NewTag(repo + ":latest")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(remote.Write(tag, img)).To(Succeed())
+		digest, err = img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+
+		trustedKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		publicKey, err := x509.MarshalPKIXPublicKey(trustedKey.Public())
+		Expect(err).ToNot(HaveOccurred())
+		kubeClient = kubefake.NewSimpleClientset()
+		unpacker = &Image{
+			APIReader: fake.NewClientBuilder().WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "signing-keys", Namespace: systemNamespace},
+				Data:       map[string][]byte{"cosign.pub": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})},
+			}).Build(),
+			KubeClient:   kubeClient,
+			PodNamespace: systemNamespace,
+		}
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type: catalogdv1alpha1.SourceTypeImage,
+					Image: &catalogdv1alpha1.ImageSource{
+						Ref: repo + ":latest",
+						Verification: &catalogdv1alpha1.ImageVerification{
+							PublicKeys: corev1.LocalObjectReference{Name: "signing-keys"},
+						},
+					},
+				},
+			},
+		}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: catalog.Name, Namespace: systemNamespace},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodSucceeded,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:    imageCatalogUnpackContainerName,
+					ImageID: repo + "@" + digest.String(),
+				}},
+			},
+		}
+	})
+
+	// podLogsFetched reports whether the logs of the unpack pod, which carry
+	// the catalog contents, were fetched.
+	podLogsFetched := func() bool {
+		for _, action := range kubeClient.Actions() {
+			if action.Matches("get", "pods") && action.GetSubresource() == "log" {
+				return true
+			}
+		}
+		return false
+	}
+
+	It("fetches the contents of an image signed by a trusted key", func() {
+		pushTestSignature(repo, digest, trustedKey)
+
+		// The fake clientset serves logs that are not catalog contents.
+		_, err := unpacker.succeededPodResult(ctx, catalog, pod)
+		Expect(IsVerificationError(err)).To(BeFalse())
+		Expect(err).To(MatchError(ContainSubstring("parse catalog data")))
+		Expect(podLogsFetched()).To(BeTrue())
+	})
+
+	It("fails verification of an unsigned image without fetching its contents", func() {
+		result, err := unpacker.succeededPodResult(ctx, catalog, pod)
+		Expect(IsVerificationError(err)).To(BeTrue())
+		Expect(result).To(BeNil())
+		Expect(podLogsFetched()).To(BeFalse())
+	})
+
+	It("fails verification of an image signed by an untrusted key without fetching its contents", func() {
+		untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		pushTestSignature(repo, digest, untrustedKey)
+
+		result, err := unpacker.succeededPodResult(ctx, catalog, pod)
+		Expect(IsVerificationError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("not signed by a trusted key")))
+		Expect(result).To(BeNil())
+		Expect(podLogsFetched()).To(BeFalse())
+	})
+})
+
+// pushTestSignature pushes a cosign style signature of the image with the
+// given digest in repo, signed by key.
+func pushTestSignature(repo string, digest ggcrv1.Hash, key crypto.Signer) {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, repo, digest))
+	hash := sha256.Sum256(payload)
+	sig, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	Expect(err).ToNot(HaveOccurred())
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+		Annotations: map[string]string{"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(sig)},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	tag, err := name.NewTag(repo + ":" + strings.Replace(digest.String(), ":", "-", 1) + ".sig")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(remote.Write(tag, img)).To(Succeed())
+}
@@ -0,0 +1,118 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// resolveImageDigest looks up the digest that the given image reference
+// currently points to in its registry, authenticating with the named pull
+// secret from the given namespace if one is provided.
+func resolveImageDigest(ctx context.Context, reader client.Reader, namespace, pullSecret, ref string) (string, error) {
+	imgRef, err := name.ParseReference(ref)
+	if err != nil {
+		return "", fmt.Errorf("parse image reference %q: %v", ref, err)
+	}
+	keychain, err := pullSecretKeychain(ctx, reader, namespace, pullSecret)
+	if err != nil {
+		return "", err
+	}
+	desc, err := remote.Head(imgRef, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return "", fmt.Errorf("resolve image digest for %q: %v", ref, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// checkDigestPolicy returns an error if the image source's digest policy
+// requires a digest reference and its reference does not contain one.
+func checkDigestPolicy(imgSource *catalogdv1alpha1.ImageSource) error {
+	if imgSource.DigestPolicy != catalogdv1alpha1.DigestPolicyRequire {
+		return nil
+	}
+	if _, err := name.NewDigest(imgSource.Ref); err != nil {
+		return fmt.Errorf("digest policy %q requires a digest reference: %v", imgSource.DigestPolicy, err)
+	}
+	return nil
+}
+
+// imageDigest returns the digest portion of a digest-based image reference,
+// such as the image ID reported in a pod's container status. An empty string
+// is returned when the reference does not contain a digest.
+func imageDigest(ref string) string {
+	idx := strings.LastIndex(ref, "@")
+	if idx < 0 {
+		return ""
+	}
+	return ref[idx+1:]
+}
+
+// pullSecretKeychain returns an authn.Keychain that serves the credentials
+// contained in the named image pull secret. When no pull secret is provided,
+// anonymous access is used.
+func pullSecretKeychain(ctx context.Context, reader client.Reader, namespace, pullSecret string) (authn.Keychain, error) {
+	if pullSecret == "" {
+		return dockerConfigKeychain{}, nil
+	}
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pullSecret}, secret); err != nil {
+		return nil, fmt.Errorf("get pull secret %s/%s: %v", namespace, pullSecret, err)
+	}
+
+	auths := map[string]authn.AuthConfig{}
+	switch {
+	case len(secret.Data[corev1.DockerConfigJsonKey]) > 0:
+		cfg := struct {
+			Auths map[string]authn.AuthConfig `json:"auths"`
+		}{}
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &cfg); err != nil {
+			return nil, fmt.Errorf("parse pull secret %s/%s: %v", namespace, pullSecret, err)
+		}
+		auths = cfg.Auths
+	case len(secret.Data[corev1.DockerConfigKey]) > 0:
+		if err := json.Unmarshal(secret.Data[corev1.DockerConfigKey], &auths); err != nil {
+			return nil, fmt.Errorf("parse pull secret %s/%s: %v", namespace, pullSecret, err)
+		}
+	default:
+		return nil, fmt.Errorf("pull secret %s/%s does not contain docker config data", namespace, pullSecret)
+	}
+
+	keychain := dockerConfigKeychain{}
+	for registry, auth := range auths {
+		keychain[normalizeRegistry(registry)] = auth
+	}
+	return keychain, nil
+}
+
+// dockerConfigKeychain is an authn.Keychain keyed by registry host.
+type dockerConfigKeychain map[string]authn.AuthConfig
+
+func (k dockerConfigKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	auth, ok := k[normalizeRegistry(target.RegistryStr())]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(auth), nil
+}
+
+// normalizeRegistry strips the scheme and path from a docker config registry
+// key and maps the various names of Docker Hub to a single host.
+func normalizeRegistry(registry string) string {
+	registry = strings.TrimPrefix(registry, "https://")
+	registry = strings.TrimPrefix(registry, "http://")
+	registry, _, _ = strings.Cut(registry, "/")
+	if registry == name.DefaultRegistry || registry == "docker.io" || registry == "registry-1.docker.io" {
+		return name.DefaultRegistry
+	}
+	return registry
+}
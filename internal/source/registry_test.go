@@ -0,0 +1,85 @@
+package source
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("pullSecretKeychain", func() {
+	const namespace = "catalogd-system"
+
+	// resolve builds a keychain from a pull secret with the given data and
+	// returns the credentials it serves for the registry of ref.
+	resolve := func(data map[string][]byte, ref string) (*authn.AuthConfig, error) {
+		reader := fake.NewClientBuilder().WithObjects(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: namespace},
+			Data:       data,
+		}).Build()
+		keychain, err := pullSecretKeychain(context.Background(), reader, namespace, "pull-secret")
+		if err != nil {
+			return nil, err
+		}
+		auth, err := keychain.Resolve(name.MustParseReference(ref).Context())
+		Expect(err).ToNot(HaveOccurred())
+		return auth.Authorization()
+	}
+
+	DescribeTable("serves the credentials of the matching registry",
+		func(data map[string][]byte, ref string, expected authn.AuthConfig) {
+			auth, err := resolve(data, ref)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auth.Username).To(Equal(expected.Username))
+			Expect(auth.Password).To(Equal(expected.Password))
+		},
+		Entry("dockerconfigjson with username and password",
+			map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"username":"user","password":"pass"}}}`)},
+			"quay.io/test/catalog:latest", authn.AuthConfig{Username: "user", Password: "pass"}),
+		Entry("dockerconfigjson with an encoded auth",
+			map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"auth":"dXNlcjpwYXNz"}}}`)},
+			"quay.io/test/catalog:latest", authn.AuthConfig{Username: "user", Password: "pass"}),
+		Entry("dockercfg keyed by URL",
+			map[string][]byte{corev1.DockerConfigKey: []byte(`{"https://registry.example.com/v1/":{"username":"user","password":"pass"}}`)},
+			"registry.example.com/test/catalog:latest", authn.AuthConfig{Username: "user", Password: "pass"}),
+		Entry("Docker Hub under its legacy index URL",
+			map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"username":"user","password":"pass"}}}`)},
+			"test/catalog:latest", authn.AuthConfig{Username: "user", Password: "pass"}),
+		Entry("no credentials for another registry",
+			map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{"quay.io":{"username":"user","password":"pass"}}}`)},
+			"registry.example.com/test/catalog:latest", authn.AuthConfig{}),
+	)
+
+	DescribeTable("rejects invalid pull secrets",
+		func(data map[string][]byte, expected string) {
+			_, err := resolve(data, "quay.io/test/catalog:latest")
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		},
+		Entry("without docker config data", map[string][]byte{"token": []byte("foo")}, "does not contain docker config data"),
+		Entry("with unparsable dockerconfigjson", map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":`)}, "parse pull secret"),
+		Entry("with unparsable dockercfg", map[string][]byte{corev1.DockerConfigKey: []byte(`[]`)}, "parse pull secret"),
+	)
+
+	It("fails when the pull secret does not exist", func() {
+		_, err := pullSecretKeychain(context.Background(), fake.NewClientBuilder().Build(), namespace, "missing")
+		Expect(err).To(MatchError(ContainSubstring("get pull secret catalogd-system/missing")))
+	})
+})
+
+var _ = DescribeTable("normalizeRegistry",
+	func(registry, expected string) {
+		Expect(normalizeRegistry(registry)).To(Equal(expected))
+	},
+	Entry("bare host", "quay.io", "quay.io"),
+	Entry("host with port", "registry.example.com:5000", "registry.example.com:5000"),
+	Entry("https URL with path", "https://registry.example.com/v2/", "registry.example.com"),
+	Entry("http URL", "http://registry.example.com", "registry.example.com"),
+	Entry("Docker Hub index URL", "https://index.docker.io/v1/", name.DefaultRegistry),
+	Entry("docker.io", "docker.io", name.DefaultRegistry),
+	Entry("registry-1.docker.io", "registry-1.docker.io", name.DefaultRegistry),
+)
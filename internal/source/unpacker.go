@@ -2,8 +2,10 @@ package source
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"time"
 
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
@@ -28,11 +30,15 @@ import (
 // For asynchronous Sources, multiple calls to Unpack should be made until the
 // returned result includes state StateUnpacked.
 //
+// Cleanup is called when a catalog is deleted, for sources to remove any
+// state, such as cached contents, that they keep for the catalog.
+//
 // NOTE: A source is meant to be agnostic to specific catalog formats and
 // specifications. A source should treat a catalog root directory as an opaque
 // file tree and delegate catalog format concerns to catalog parsers.
 type Unpacker interface {
 	Unpack(context.Context, *catalogdv1alpha1.Catalog) (*Result, error)
+	Cleanup(context.Context, *catalogdv1alpha1.Catalog) error
 }
 
 // Result conveys progress information about unpacking catalog content.
@@ -57,6 +63,23 @@ type Result struct {
 	// Message is contextual information about the progress of unpacking the
 	// catalog content.
 	Message string
+
+	// LatestDigest is the digest that the image reference of a catalog whose
+	// digest policy is ReportDrift currently points to, which may differ from
+	// the digest of the ResolvedSource. It is empty when it could not be
+	// determined.
+	LatestDigest string
+
+	// Deadline is the time at which a source that is still pending or
+	// unpacking gives up on unpacking the catalog content, if it has one.
+	// Callers should call Unpack again by then so that the failure is reported.
+	Deadline time.Time
+
+	// StartTime is the time at which the attempt to unpack the catalog
+	// content that produced this result started, for sources that unpack
+	// asynchronously across calls to Unpack. It is zero for sources that
+	// unpack the catalog content within a single call to Unpack.
+	StartTime time.Time
 }
 
 type State string
@@ -75,6 +98,30 @@ const (
 	StateUnpacked State = "Unpacked"
 )
 
+// UnpackTimeoutError is returned by image sources when catalog content is not
+// unpacked within the unpack timeout of the catalog.
+type UnpackTimeoutError struct {
+	// Timeout is the unpack timeout that was exceeded.
+	Timeout time.Duration
+	// Message is contextual information about the progress that was made
+	// before the timeout was exceeded.
+	Message string
+}
+
+func (e *UnpackTimeoutError) Error() string {
+	msg := fmt.Sprintf("catalog was not unpacked within %s", e.Timeout)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// IsUnpackTimeoutError reports whether err is, or wraps, an UnpackTimeoutError.
+func IsUnpackTimeoutError(err error) bool {
+	var timeoutErr *UnpackTimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
 type unpacker struct {
 	sources map[catalogdv1alpha1.SourceType]Unpacker
 }
@@ -93,23 +140,90 @@ func (s *unpacker) Unpack(ctx context.Context, catalog *catalogdv1alpha1.Catalog
 	return source.Unpack(ctx, catalog)
 }
 
+func (s *unpacker) Cleanup(ctx context.Context, catalog *catalogdv1alpha1.Catalog) error {
+	source, ok := s.sources[catalog.Spec.Source.Type]
+	if !ok {
+		// nothing was unpacked for a source type that is not supported
+		return nil
+	}
+	return source.Cleanup(ctx, catalog)
+}
+
+// UnpackerOptions configures the unpackers created by NewDefaultUnpacker.
+type UnpackerOptions struct {
+	// Namespace is the namespace in which unpack pods are run and from which
+	// secrets and configmaps referenced by catalog sources are read.
+	Namespace string
+
+	// UnpackImage is the image used by unpack pods.
+	UnpackImage string
+
+	// UnpackTimeout is the time that unpack pods, or direct unpacks of
+	// images, may take to complete when a catalog does not set its own unpack
+	// timeout. Zero disables the timeout.
+	UnpackTimeout time.Duration
+
+	// DirectImageUnpack unpacks image sources within the catalogd process
+	// rather than with unpack pods.
+	DirectImageUnpack bool
+
+	// CacheDir is the directory in which directly unpacked images are cached.
+	CacheDir string
+
+	// RegistryMirrors maps registry hosts to the mirrors that should be tried
+	// first when directly unpacking images.
+	RegistryMirrors map[string]string
+
+	// RegistryCAFile is a PEM encoded CA bundle trusted, in addition to the
+	// system roots, when directly unpacking images.
+	RegistryCAFile string
+}
+
 // NewDefaultUnpacker returns a new composite Source that unpacks catalogs using
 // a default source mapping with built-in implementations of all of the supported
 // source types.
-//
-// TODO: refactor NewDefaultUnpacker due to growing parameter list
-func NewDefaultUnpacker(systemNsCluster cluster.Cluster, namespace, unpackImage string) (Unpacker, error) {
-	cfg := systemNsCluster.GetConfig()
-	kubeClient, err := kubernetes.NewForConfig(cfg)
-	if err != nil {
-		return nil, err
+func NewDefaultUnpacker(systemNsCluster cluster.Cluster, opts UnpackerOptions) (Unpacker, error) {
+	var imageUnpacker Unpacker
+	if opts.DirectImageUnpack {
+		transport, err := registryTransport(opts.RegistryCAFile)
+		if err != nil {
+			return nil, err
+		}
+		imageUnpacker = &ImageRegistry{
+			Reader:          systemNsCluster.GetAPIReader(),
+			SecretNamespace: opts.Namespace,
+			CacheDir:        opts.CacheDir,
+			Mirrors:         opts.RegistryMirrors,
+			Transport:       transport,
+			UnpackTimeout:   opts.UnpackTimeout,
+		}
+	} else {
+		kubeClient, err := kubernetes.NewForConfig(systemNsCluster.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+		imageUnpacker = &Image{
+			Client:        systemNsCluster.GetClient(),
+			APIReader:     systemNsCluster.GetAPIReader(),
+			KubeClient:    kubeClient,
+			PodNamespace:  opts.Namespace,
+			UnpackImage:   opts.UnpackImage,
+			UnpackTimeout: opts.UnpackTimeout,
+		}
 	}
 	return NewUnpacker(map[catalogdv1alpha1.SourceType]Unpacker{
-		catalogdv1alpha1.SourceTypeImage: &Image{
-			Client:       systemNsCluster.GetClient(),
-			KubeClient:   kubeClient,
-			PodNamespace: namespace,
-			UnpackImage:  unpackImage,
+		catalogdv1alpha1.SourceTypeImage: imageUnpacker,
+		catalogdv1alpha1.SourceTypeGit: &Git{
+			Reader:          systemNsCluster.GetAPIReader(),
+			SecretNamespace: opts.Namespace,
+		},
+		catalogdv1alpha1.SourceTypeHTTP: &HTTP{
+			Reader:          systemNsCluster.GetAPIReader(),
+			SecretNamespace: opts.Namespace,
+		},
+		catalogdv1alpha1.SourceTypeConfigMaps: &ConfigMaps{
+			Reader:             systemNsCluster.GetAPIReader(),
+			ConfigMapNamespace: opts.Namespace,
 		},
 	}), nil
 }
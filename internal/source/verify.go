@@ -0,0 +1,210 @@
+package source
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// The annotations, tag suffix and payload type of the signatures that cosign
+// attaches to images. Each layer of the signature image is a signed payload
+// that identifies the digest of the signed image.
+const (
+	signatureAnnotation  = "dev.cosignproject.cosign/signature"
+	signatureTagSuffix   = ".sig"
+	signaturePayloadType = "cosign container image signature"
+
+	// maxSignaturePayloadSize bounds the size of the signed payloads that are
+	// read from a registry.
+	maxSignaturePayloadSize = 1 << 20
+)
+
+// VerificationError is returned by image sources when a catalog image does
+// not satisfy the verification policy of its source, either because it has
+// no signatures or because none of its signatures are trusted.
+type VerificationError struct {
+	// Ref is the digest reference of the image that failed verification.
+	Ref string
+	// Reason describes why the image failed verification.
+	Reason string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verify signatures of image %q: %s", e.Ref, e.Reason)
+}
+
+// IsVerificationError reports whether err is, or wraps, a VerificationError.
+func IsVerificationError(err error) bool {
+	var verificationErr *VerificationError
+	return errors.As(err, &verificationErr)
+}
+
+// verifyImage verifies that the image identified by ref carries a valid
+// signature that is trusted by the given policy. The public keys of the
+// policy are read from a secret in namespace, and fetch is used to fetch the
+// image that holds the signatures of ref from its registry.
+func verifyImage(ctx context.Context, reader client.Reader, namespace string, policy *catalogdv1alpha1.ImageVerification, ref name.Digest, fetch func(name.Reference) (v1.Image, error)) error {
+	if policy == nil {
+		return nil
+	}
+	v, err := newVerifier(ctx, reader, namespace, policy)
+	if err != nil {
+		return err
+	}
+
+	sigRef := ref.Context().Tag(strings.Replace(ref.DigestStr(), ":", "-", 1) + signatureTagSuffix)
+	sigImg, err := fetch(sigRef)
+	if err != nil {
+		var transportErr *transport.Error
+		if errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound {
+			return &VerificationError{Ref: ref.String(), Reason: "no signatures found"}
+		}
+		return fmt.Errorf("fetch signatures of image %q: %v", ref, err)
+	}
+	manifest, err := sigImg.Manifest()
+	if err != nil {
+		return fmt.Errorf("get signature manifest of image %q: %v", ref, err)
+	}
+	if len(manifest.Layers) == 0 {
+		return &VerificationError{Ref: ref.String(), Reason: "no signatures found"}
+	}
+
+	var reasons []string
+	for _, desc := range manifest.Layers {
+		layer, err := sigImg.LayerByDigest(desc.Digest)
+		if err != nil {
+			return fmt.Errorf("get signature %s of image %q: %v", desc.Digest, ref, err)
+		}
+		if err := v.verifySignature(desc, layer, ref.DigestStr()); err != nil {
+			reasons = append(reasons, fmt.Sprintf("signature %s: %v", desc.Digest, err))
+			continue
+		}
+		return nil
+	}
+	return &VerificationError{Ref: ref.String(), Reason: "no trusted signatures found: " + strings.Join(reasons, "; ")}
+}
+
+// verifier verifies signatures against the public keys of a verification
+// policy.
+type verifier struct {
+	publicKeys []crypto.PublicKey
+}
+
+func newVerifier(ctx context.Context, reader client.Reader, namespace string, policy *catalogdv1alpha1.ImageVerification) (*verifier, error) {
+	secret, err := getSecret(ctx, reader, namespace, policy.PublicKeys.Name)
+	if err != nil {
+		return nil, err
+	}
+	v := &verifier{}
+	for _, key := range sets.List(sets.KeySet(secret.Data)) {
+		block, _ := pem.Decode(secret.Data[key])
+		if block == nil {
+			return nil, fmt.Errorf("public key %q of secret %s/%s is not PEM encoded", key, namespace, secret.Name)
+		}
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key %q of secret %s/%s: %v", key, namespace, secret.Name, err)
+		}
+		v.publicKeys = append(v.publicKeys, publicKey)
+	}
+	if len(v.publicKeys) == 0 {
+		return nil, fmt.Errorf("secret %s/%s contains no public keys", namespace, secret.Name)
+	}
+	return v, nil
+}
+
+func getSecret(ctx context.Context, reader client.Reader, namespace, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("get verification secret %s/%s: %v", namespace, name, err)
+	}
+	return secret, nil
+}
+
+// verifySignature verifies that the payload in layer is signed by a trusted
+// key and that it identifies the image with the given digest.
+func (v *verifier) verifySignature(desc v1.Descriptor, layer v1.Layer, digest string) error {
+	encoded, ok := desc.Annotations[signatureAnnotation]
+	if !ok {
+		return errors.New("missing signature annotation")
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decode signature: %v", err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		return fmt.Errorf("read payload: %v", err)
+	}
+	defer rc.Close()
+	payload, err := io.ReadAll(io.LimitReader(rc, maxSignaturePayloadSize))
+	if err != nil {
+		return fmt.Errorf("read payload: %v", err)
+	}
+
+	trusted := false
+	for _, key := range v.publicKeys {
+		if verifyPayload(key, payload, sig) {
+			trusted = true
+			break
+		}
+	}
+	if !trusted {
+		return errors.New("not signed by a trusted key")
+	}
+
+	var simpleSigning struct {
+		Critical struct {
+			Type  string `json:"type"`
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+		return fmt.Errorf("parse payload: %v", err)
+	}
+	if simpleSigning.Critical.Type != signaturePayloadType {
+		return fmt.Errorf("unexpected payload type %q", simpleSigning.Critical.Type)
+	}
+	if simpleSigning.Critical.Image.DockerManifestDigest != digest {
+		return fmt.Errorf("payload is for image digest %q", simpleSigning.Critical.Image.DockerManifestDigest)
+	}
+	return nil
+}
+
+// verifyPayload reports whether sig is a valid signature of payload by key.
+func verifyPayload(key crypto.PublicKey, payload, sig []byte) bool {
+	digest := sha256.Sum256(payload)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, digest[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, payload, sig)
+	default:
+		return false
+	}
+}
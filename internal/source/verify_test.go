@@ -0,0 +1,129 @@
+package source_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	catalogdv1alpha1 "github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/catalogd/internal/source"
+)
+
+var _ = Describe("Image verification", func() {
+	const (
+		testPackage     = "schema: olm.package\nname: foo\n"
+		systemNamespace = "catalogd-system"
+	)
+
+	var (
+		ctx        context.Context
+		host       string
+		repo       string
+		digest     v1.Hash
+		trustedKey *ecdsa.PrivateKey
+		unpacker   *source.ImageRegistry
+		catalog    *catalogdv1alpha1.Catalog
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		host = newTestRegistry()
+		repo = host + "/catalogs/test"
+		digest = pushCatalogImage(repo+":latest", nil, map[string]string{"configs/foo/package.yaml": testPackage})
+
+		trustedKey = newKey()
+		publicKey, err := x509.MarshalPKIXPublicKey(trustedKey.Public())
+		Expect(err).ToNot(HaveOccurred())
+		unpacker = &source.ImageRegistry{
+			Reader: fake.NewClientBuilder().WithObjects(
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "signing-keys", Namespace: systemNamespace},
+					Data:       map[string][]byte{"cosign.pub": pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})},
+				},
+			).Build(),
+			SecretNamespace: systemNamespace,
+			CacheDir:        GinkgoT().TempDir(),
+		}
+		catalog = &catalogdv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: catalogdv1alpha1.CatalogSpec{
+				Source: catalogdv1alpha1.CatalogSource{
+					Type: catalogdv1alpha1.SourceTypeImage,
+					Image: &catalogdv1alpha1.ImageSource{
+						Ref: repo + ":latest",
+						Verification: &catalogdv1alpha1.ImageVerification{
+							PublicKeys: corev1.LocalObjectReference{Name: "signing-keys"},
+						},
+					},
+				},
+			},
+		}
+	})
+
+	It("unpacks an image signed by a trusted public key", func() {
+		pushSignature(repo, digest, trustedKey)
+
+		result, err := unpacker.Unpack(ctx, catalog)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.State).To(Equal(source.StateUnpacked))
+	})
+
+	It("fails verification when the image has no signatures", func() {
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(source.IsVerificationError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("no signatures found")))
+	})
+
+	It("fails verification when the image is signed by an untrusted key", func() {
+		pushSignature(repo, digest, newKey())
+
+		_, err := unpacker.Unpack(ctx, catalog)
+		Expect(source.IsVerificationError(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("not signed by a trusted key")))
+	})
+})
+
+func newKey() *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	return key
+}
+
+// pushSignature pushes a cosign style signature of the image with the given
+// digest in repo, signed by key.
+func pushSignature(repo string, digest v1.Hash, key crypto.Signer) {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":%q},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, repo, digest))
+	hash := sha256.Sum256(payload)
+	sig, err := key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	Expect(err).ToNot(HaveOccurred())
+
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       static.NewLayer(payload, "application/vnd.dev.cosign.simplesigning.v1+json"),
+		Annotations: map[string]string{"dev.cosignproject.cosign/signature": base64.StdEncoding.EncodeToString(sig)},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	tag, err := name.NewTag(repo + ":" + strings.Replace(digest.String(), ":", "-", 1) + ".sig")
+	Expect(err).ToNot(HaveOccurred())
+	Expect(remote.Write(tag, img)).To(Succeed())
+}
@@ -17,46 +17,106 @@ limitations under the License.
 package core
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apimacherrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	"github.com/operator-framework/catalogd/api/core/v1alpha1"
 	"github.com/operator-framework/catalogd/internal/source"
+	"github.com/operator-framework/catalogd/pkg/metrics"
+	"github.com/operator-framework/catalogd/pkg/names"
+	"github.com/operator-framework/catalogd/pkg/storage"
 )
 
-// TODO (everettraven): Add unit tests for the CatalogReconciler
-
 // CatalogReconciler reconciles a Catalog object
 type CatalogReconciler struct {
 	client.Client
 	Unpacker source.Unpacker
+	Recorder record.EventRecorder
+
+	// Storage, when set, stores the unpacked contents of each Catalog so that
+	// they can be served over HTTP.
+	Storage storage.Instance
+
+	// MaxUnpackBackoff caps the exponential backoff with which failed
+	// attempts to unpack and sync a Catalog are retried. When zero, failed
+	// attempts return their error and retries are left to the rate limiter
+	// of the controller.
+	MaxUnpackBackoff time.Duration
 }
 
+// initialUnpackBackoff is the time after which the first failed attempt to
+// unpack and sync a Catalog is retried. The backoff doubles with every
+// further failed attempt, up to the MaxUnpackBackoff of the reconciler.
+const initialUnpackBackoff = 10 * time.Second
+
+// maxEventMessageLength is the length beyond which the messages of recorded
+// events, such as those containing the logs of a failed unpack pod, are
+// truncated.
+const maxEventMessageLength = 1024
+
+// defaultMaxInlineBundleObjectSize is the maximum size of an
+// olm.bundle.object property value that is kept inline in its BundleMetadata
+// when the Catalog does not configure one.
+const defaultMaxInlineBundleObjectSize = 4096
+
+// maxBundleObjectDataSize is the maximum size of the data of a BundleObject.
+// The data is stored base64 encoded, so this keeps BundleObjects within the
+// default etcd request size limit of 1.5MiB with room for their metadata.
+const maxBundleObjectDataSize = 1 << 20
+
+// maxCatalogMetadataContentSize is the maximum size of the content of a
+// CatalogMetadata, which keeps it within the default etcd request size limit
+// of 1.5MiB with room for its metadata.
+const maxCatalogMetadataContentSize = 1 << 20
+
+// fbcDeletionFinalizer is the finalizer that ensures the stored contents of a
+// Catalog, and any contents cached by its source, are deleted along with it.
+const fbcDeletionFinalizer = "catalogd.operatorframework.io/delete-server-cache"
+
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=catalogs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=catalogs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=catalogs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=catalogmetadata,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=bundlemetadata,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=bundlemetadata/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=bundlemetadata/finalizers,verbs=update
+//+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=bundleobjects,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=packages,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=packages/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=catalogd.operatorframework.io,resources=packages/finalizers,verbs=update
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=create;update;patch;delete;get;list;watch
 //+kubebuilder:rbac:groups=core,resources=pods/log,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get,namespace=system
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get,namespace=system
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -69,7 +129,13 @@ func (r *CatalogReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	existingCatsrc := v1alpha1.Catalog{}
 	if err := r.Client.Get(ctx, req.NamespacedName, &existingCatsrc); err != nil {
-		return ctrl.Result{}, client.IgnoreNotFound(err)
+		if apierrors.IsNotFound(err) {
+			metrics.DeleteCatalog(req.Name)
+			// the packages of the deleted catalog may have been preferred
+			// over those of other catalogs
+			return ctrl.Result{}, r.syncPackagePrecedence(ctx, nil, nil)
+		}
+		return ctrl.Result{}, err
 	}
 
 	reconciledCatsrc := existingCatsrc.DeepCopy()
@@ -111,46 +177,304 @@ func (r *CatalogReconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 func (r *CatalogReconciler) reconcile(ctx context.Context, catalog *v1alpha1.Catalog) (ctrl.Result, error) {
+	if r.Storage != nil {
+		if !catalog.DeletionTimestamp.IsZero() {
+			if err := r.Storage.Delete(catalog.Name); err != nil {
+				return ctrl.Result{}, fmt.Errorf("delete stored catalog contents: %v", err)
+			}
+			if err := r.Unpacker.Cleanup(ctx, catalog); err != nil {
+				return ctrl.Result{}, fmt.Errorf("clean up unpacked catalog contents: %v", err)
+			}
+			controllerutil.RemoveFinalizer(catalog, fbcDeletionFinalizer)
+			return ctrl.Result{}, nil
+		}
+		if controllerutil.AddFinalizer(catalog, fbcDeletionFinalizer) {
+			// Persist the finalizer before any contents are stored, as the
+			// status update made after storing them would drop it.
+			return ctrl.Result{Requeue: true}, nil
+		}
+	}
+
+	if catalog.Spec.Suspend {
+		return ctrl.Result{}, nil
+	}
+	if wait := r.retryAfter(catalog); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	unpackStart := time.Now()
+	inProgress := catalog.Status.Phase == v1alpha1.PhasePending || catalog.Status.Phase == v1alpha1.PhaseUnpacking
 	unpackResult, err := r.Unpacker.Unpack(ctx, catalog)
 	if err != nil {
-		return ctrl.Result{}, updateStatusUnpackFailing(&catalog.Status, fmt.Errorf("source bundle content: %v", err))
+		switch {
+		case source.IsVerificationError(err):
+			return r.unpackFailing(catalog, metrics.FailureReasonVerify, err)
+		case source.IsUnpackTimeoutError(err):
+			return r.unpackFailing(catalog, metrics.FailureReasonTimeout, err)
+		}
+		return r.unpackFailing(catalog, metrics.FailureReasonUnpack, fmt.Errorf("source bundle content: %v", err))
 	}
 
 	switch unpackResult.State {
 	case source.StatePending:
+		r.recordUnpackStarted(catalog)
 		updateStatusUnpackPending(&catalog.Status, unpackResult)
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: untilDeadline(unpackResult)}, nil
 	case source.StateUnpacking:
+		r.recordUnpackStarted(catalog)
 		updateStatusUnpacking(&catalog.Status, unpackResult)
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: untilDeadline(unpackResult)}, nil
 	case source.StateUnpacked:
-		// TODO: We should check to see if the unpacked result has the same content
-		//   as the already unpacked content. If it does, we should skip this rest
-		//   of the unpacking steps.
+		if inProgress && !unpackResult.StartTime.IsZero() {
+			unpackStart = unpackResult.StartTime
+		}
+		digest, size, err := contentDigest(unpackResult.FS)
+		if err != nil {
+			return r.unpackFailing(catalog, metrics.FailureReasonUnpack, fmt.Errorf("compute catalog content digest: %v", err))
+		}
+		metrics.ContentSize.WithLabelValues(catalog.Name).Set(float64(size))
+		if catalog.Status.Phase == v1alpha1.PhaseUnpacked && catalog.Status.ContentDigest == digest &&
+			catalog.Status.ObservedGeneration == catalog.Generation && r.contentStored(catalog) {
+			updateStatusUnpacked(&catalog.Status, unpackResult, digest)
+			r.updateSourceDrift(catalog, unpackResult)
+			return ctrl.Result{RequeueAfter: pollInterval(catalog)}, nil
+		}
+		// Only unpacks of new content are recorded, not polls that find the
+		// content unchanged.
+		metrics.UnpackDuration.WithLabelValues(string(catalog.Spec.Source.Type)).Observe(time.Since(unpackStart).Seconds())
+		// Sources that unpack within a single call to Unpack go straight
+		// to Unpacked, so the start of the attempt is recorded here.
+		r.recordUnpackStarted(catalog)
 
 		fbc, err := declcfg.LoadFS(unpackResult.FS)
 		if err != nil {
-			return ctrl.Result{}, updateStatusUnpackFailing(&catalog.Status, fmt.Errorf("load FBC from filesystem: %v", err))
+			return r.unpackFailing(catalog, metrics.FailureReasonLoad, fmt.Errorf("load FBC from filesystem: %v", err))
+		}
+		fbc, err = filterContents(fbc, catalog.Spec.Filter)
+		if err != nil {
+			return r.unpackFailing(catalog, metrics.FailureReasonFilter, fmt.Errorf("filter FBC: %v", err))
 		}
 
-		if err := r.syncPackages(ctx, fbc, catalog); err != nil {
-			return ctrl.Result{}, updateStatusUnpackFailing(&catalog.Status, fmt.Errorf("create package objects: %v", err))
+		if r.Storage != nil {
+			if err := r.Storage.Store(catalog.Name, fbc); err != nil {
+				return r.unpackFailing(catalog, metrics.FailureReasonStore, fmt.Errorf("store catalog contents: %v", err))
+			}
+			catalog.Status.ContentURL = r.Storage.ContentURL(catalog.Name)
 		}
 
-		if err := r.syncBundleMetadata(ctx, fbc, catalog); err != nil {
-			return ctrl.Result{}, updateStatusUnpackFailing(&catalog.Status, fmt.Errorf("create bundle metadata objects: %v", err))
+		prunedPkgs, err := r.syncPackages(ctx, fbc, catalog)
+		if err != nil {
+			return r.unpackFailing(catalog, metrics.FailureReasonSyncPackages, fmt.Errorf("create package objects: %v", err))
 		}
 
-		updateStatusUnpacked(&catalog.Status, unpackResult)
-		return ctrl.Result{}, nil
+		prunedBundles, err := r.syncBundleMetadata(ctx, fbc, catalog)
+		if err != nil {
+			return r.unpackFailing(catalog, metrics.FailureReasonSyncBundleMetadata, fmt.Errorf("create bundle metadata objects: %v", err))
+		}
+		prunedMetadata, err := r.syncCatalogMetadata(ctx, fbc, catalog)
+		if err != nil {
+			return r.unpackFailing(catalog, metrics.FailureReasonSyncCatalogMetadata, fmt.Errorf("create catalog metadata objects: %v", err))
+		}
+		if prunedPkgs > 0 || prunedBundles > 0 || prunedMetadata > 0 {
+			r.Recorder.Eventf(catalog, corev1.EventTypeNormal, "Pruned", "deleted %d Packages, %d BundleMetadata and %d CatalogMetadata no longer in the catalog contents", prunedPkgs, prunedBundles, prunedMetadata)
+		}
+
+		updateStatusUnpacked(&catalog.Status, unpackResult, digest)
+		r.updateSourceDrift(catalog, unpackResult)
+		catalog.Status.ObservedGeneration = catalog.Generation
+		r.Recorder.Event(catalog, corev1.EventTypeNormal, v1alpha1.ReasonUnpackSuccessful, truncate(unpackResult.Message))
+		return ctrl.Result{RequeueAfter: pollInterval(catalog)}, nil
 	default:
-		return ctrl.Result{}, updateStatusUnpackFailing(&catalog.Status, fmt.Errorf("unknown unpack state %q: %v", unpackResult.State, err))
+		return r.unpackFailing(catalog, metrics.FailureReasonUnpack, fmt.Errorf("unknown unpack state %q: %v", unpackResult.State, err))
+	}
+
+}
+
+// contentStored reports whether the contents of the catalog are stored, or
+// whether there is no storage to store them in.
+func (r *CatalogReconciler) contentStored(catalog *v1alpha1.Catalog) bool {
+	return r.Storage == nil || r.Storage.ContentExists(catalog.Name)
+}
+
+// pollInterval returns the interval after which the catalog's source should
+// be checked for new content, or zero if the source is not polled.
+func pollInterval(catalog *v1alpha1.Catalog) time.Duration {
+	if catalog.Spec.Source.Image == nil || catalog.Spec.Source.Image.PollInterval == nil {
+		return 0
+	}
+	return catalog.Spec.Source.Image.PollInterval.Duration
+}
+
+// retryAfter returns the time left until the failed attempts to unpack and
+// sync the catalog may be retried, or zero if they may be retried now. The
+// backoff is skipped when the catalog's spec changed since the last attempt
+// failed, so that fixes to its spec are picked up right away.
+func (r *CatalogReconciler) retryAfter(catalog *v1alpha1.Catalog) time.Duration {
+	status := catalog.Status
+	if r.MaxUnpackBackoff <= 0 || status.Phase != v1alpha1.PhaseFailing || status.UnpackAttempts == 0 || status.LastUnpackAttemptTime == nil {
+		return 0
+	}
+	cond := meta.FindStatusCondition(status.Conditions, v1alpha1.TypeUnpacked)
+	if cond == nil || cond.ObservedGeneration != catalog.Generation {
+		return 0
+	}
+	return time.Until(status.LastUnpackAttemptTime.Add(r.unpackBackoff(status.UnpackAttempts)))
+}
+
+// unpackBackoff returns the backoff after the given number of failed attempts.
+func (r *CatalogReconciler) unpackBackoff(attempts int32) time.Duration {
+	backoff := initialUnpackBackoff
+	for i := int32(1); i < attempts && backoff < r.MaxUnpackBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.MaxUnpackBackoff {
+		return r.MaxUnpackBackoff
 	}
+	return backoff
+}
+
+// untilDeadline returns the time until the deadline of a pending or unpacking
+// result, after which the catalog must be reconciled again for the source to
+// fail the unpack, or zero if the result has no deadline.
+func untilDeadline(result *source.Result) time.Duration {
+	if result.Deadline.IsZero() {
+		return 0
+	}
+	if d := time.Until(result.Deadline); d > 0 {
+		return d
+	}
+	return time.Second
+}
+
+// contentDigest returns a digest of the names, modes, and contents of every
+// file and directory in the given filesystem, along with the total size of
+// its files.
+func contentDigest(fsys fs.FS) (string, int64, error) {
+	var size int64
+	h := sha256.New()
+	if err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%s\x00", path, info.Mode().Type())
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		fmt.Fprintf(h, "%d\x00", info.Size())
+		n, err := io.Copy(h, f)
+		size += n
+		return err
+	}); err != nil {
+		return "", 0, err
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), size, nil
+}
+
+// unpackFailing records a failure to unpack and sync the catalog for the
+// given reason and updates its status to reflect the failure. When the
+// reconciler backs off failed attempts, the catalog is requeued after the
+// backoff instead of returning the error, which would requeue it with the
+// rate limiter of the controller.
+func (r *CatalogReconciler) unpackFailing(catalog *v1alpha1.Catalog, reason string, err error) (ctrl.Result, error) {
+	metrics.UnpackFailures.WithLabelValues(string(catalog.Spec.Source.Type), reason).Inc()
+	conditionReason := v1alpha1.ReasonUnpackFailed
+	switch reason {
+	case metrics.FailureReasonVerify:
+		conditionReason = v1alpha1.ReasonVerificationFailed
+	case metrics.FailureReasonTimeout:
+		conditionReason = v1alpha1.ReasonUnpackTimeout
+	}
+	r.Recorder.Event(catalog, corev1.EventTypeWarning, conditionReason, truncate(err.Error()))
+	catalog.Status.UnpackAttempts++
+	now := metav1.Now()
+	catalog.Status.LastUnpackAttemptTime = &now
+	err = updateStatusUnpackFailing(&catalog.Status, catalog.Generation, conditionReason, err)
+	if r.MaxUnpackBackoff > 0 {
+		return ctrl.Result{RequeueAfter: r.unpackBackoff(catalog.Status.UnpackAttempts)}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// recordUnpackStarted records an event for the start of an attempt to unpack
+// the catalog, unless the attempt was already started and recorded while the
+// catalog was pending or unpacking.
+func (r *CatalogReconciler) recordUnpackStarted(catalog *v1alpha1.Catalog) {
+	if catalog.Status.Phase == v1alpha1.PhasePending || catalog.Status.Phase == v1alpha1.PhaseUnpacking {
+		return
+	}
+	r.Recorder.Eventf(catalog, corev1.EventTypeNormal, "UnpackStarted", "started unpacking the catalog from its %s source", catalog.Spec.Source.Type)
+}
+
+// updateSourceDrift pins the digest that a catalog whose digest policy is
+// ReportDrift was unpacked from, and records the digest that its image
+// reference currently points to and whether it has drifted from the unpacked
+// digest. The last known drift is kept when the current digest could not be
+// determined.
+func (r *CatalogReconciler) updateSourceDrift(catalog *v1alpha1.Catalog, result *source.Result) {
+	imgSource := catalog.Spec.Source.Image
+	if imgSource == nil || imgSource.DigestPolicy != v1alpha1.DigestPolicyReportDrift {
+		catalog.Status.PinnedImage = nil
+		clearSourceDrift(&catalog.Status)
+		return
+	}
+	if result.ResolvedSource == nil || result.ResolvedSource.Image == nil {
+		return
+	}
+	if i := strings.LastIndex(result.ResolvedSource.Image.Ref, "@"); i >= 0 {
+		catalog.Status.PinnedImage = &v1alpha1.PinnedImage{Ref: imgSource.Ref, Digest: result.ResolvedSource.Image.Ref[i+1:]}
+	}
+	if result.LatestDigest == "" {
+		return
+	}
+	catalog.Status.LatestImageDigest = result.LatestDigest
+	unpacked := result.ResolvedSource.Image.Ref
+	if strings.HasSuffix(unpacked, "@"+result.LatestDigest) {
+		meta.SetStatusCondition(&catalog.Status.Conditions, metav1.Condition{
+			Type:    v1alpha1.TypeSourceDrifted,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.ReasonDigestUnchanged,
+			Message: fmt.Sprintf("image reference %q points to the unpacked image", imgSource.Ref),
+		})
+		return
+	}
+	message := fmt.Sprintf("image reference %q points to %s, but %q remains unpacked until the reference is updated", imgSource.Ref, result.LatestDigest, unpacked)
+	if !meta.IsStatusConditionTrue(catalog.Status.Conditions, v1alpha1.TypeSourceDrifted) {
+		r.Recorder.Event(catalog, corev1.EventTypeWarning, v1alpha1.ReasonDigestChanged, truncate(message))
+	}
+	meta.SetStatusCondition(&catalog.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.TypeSourceDrifted,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.ReasonDigestChanged,
+		Message: message,
+	})
+}
 
+func clearSourceDrift(status *v1alpha1.CatalogStatus) {
+	status.LatestImageDigest = ""
+	meta.RemoveStatusCondition(&status.Conditions, v1alpha1.TypeSourceDrifted)
+}
+
+// truncate shortens the message to at most maxEventMessageLength bytes.
+func truncate(message string) string {
+	if len(message) <= maxEventMessageLength {
+		return message
+	}
+	return message[:maxEventMessageLength-3] + "..."
 }
 
 func updateStatusUnpackPending(status *v1alpha1.CatalogStatus, result *source.Result) {
 	status.ResolvedSource = nil
+	clearSourceDrift(status)
+	status.ContentDigest = ""
 	status.Phase = v1alpha1.PhasePending
 	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
 		Type:    v1alpha1.TypeUnpacked,
@@ -162,6 +486,8 @@ func updateStatusUnpackPending(status *v1alpha1.CatalogStatus, result *source.Re
 
 func updateStatusUnpacking(status *v1alpha1.CatalogStatus, result *source.Result) {
 	status.ResolvedSource = nil
+	clearSourceDrift(status)
+	status.ContentDigest = ""
 	status.Phase = v1alpha1.PhaseUnpacking
 	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
 		Type:    v1alpha1.TypeUnpacked,
@@ -171,8 +497,11 @@ func updateStatusUnpacking(status *v1alpha1.CatalogStatus, result *source.Result
 	})
 }
 
-func updateStatusUnpacked(status *v1alpha1.CatalogStatus, result *source.Result) {
+func updateStatusUnpacked(status *v1alpha1.CatalogStatus, result *source.Result, digest string) {
 	status.ResolvedSource = result.ResolvedSource
+	status.UnpackAttempts = 0
+	status.LastUnpackAttemptTime = nil
+	status.ContentDigest = digest
 	status.Phase = v1alpha1.PhaseUnpacked
 	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
 		Type:    v1alpha1.TypeUnpacked,
@@ -182,51 +511,53 @@ func updateStatusUnpacked(status *v1alpha1.CatalogStatus, result *source.Result)
 	})
 }
 
-func updateStatusUnpackFailing(status *v1alpha1.CatalogStatus, err error) error {
+func updateStatusUnpackFailing(status *v1alpha1.CatalogStatus, generation int64, reason string, err error) error {
 	status.ResolvedSource = nil
+	clearSourceDrift(status)
+	status.ContentDigest = ""
 	status.Phase = v1alpha1.PhaseFailing
 	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
-		Type:    v1alpha1.TypeUnpacked,
-		Status:  metav1.ConditionFalse,
-		Reason:  v1alpha1.ReasonUnpackFailed,
-		Message: err.Error(),
+		Type:               v1alpha1.TypeUnpacked,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            err.Error(),
+		ObservedGeneration: generation,
 	})
 	return err
 }
 
 // syncBundleMetadata will create a `BundleMetadata` resource for each
-// "olm.bundle" object that exists for the given catalog contents. Returns an
+// "olm.bundle" object that exists for the given catalog contents. Existing
+// `BundleMetadata` resources of the same catalog that are no longer present
+// in its contents are deleted. Returns the number of deleted resources, or an
 // error if any are encountered.
-func (r *CatalogReconciler) syncBundleMetadata(ctx context.Context, declCfg *declcfg.DeclarativeConfig, catalog *v1alpha1.Catalog) error {
+func (r *CatalogReconciler) syncBundleMetadata(ctx context.Context, declCfg *declcfg.DeclarativeConfig, catalog *v1alpha1.Catalog) (int, error) {
+	defer observeSyncDuration(metrics.ResourceBundleMetadata, time.Now())
 	newBundles := map[string]*v1alpha1.BundleMetadata{}
+	newBundleObjects := map[string]*v1alpha1.BundleObject{}
+	channels := bundleChannels(declCfg.Channels)
+	deprecations, err := parseDeprecations(declCfg)
+	if err != nil {
+		return 0, err
+	}
 
 	for _, bundle := range declCfg.Bundles {
-		bundleName := fmt.Sprintf("%s-%s", catalog.Name, bundle.Name)
+		bundleName := names.ForCatalogObject(catalog.Name, bundle.Name)
 
 		bundleMeta := v1alpha1.BundleMetadata{
 			TypeMeta: metav1.TypeMeta{
 				APIVersion: v1alpha1.GroupVersion.String(),
 				Kind:       "BundleMetadata",
 			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name: bundleName,
-				Labels: map[string]string{
-					"catalog": catalog.Name,
-				},
-				OwnerReferences: []metav1.OwnerReference{{
-					APIVersion:         v1alpha1.GroupVersion.String(),
-					Kind:               "Catalog",
-					Name:               catalog.Name,
-					UID:                catalog.UID,
-					BlockOwnerDeletion: pointer.Bool(true),
-					Controller:         pointer.Bool(true),
-				}},
-			},
+			ObjectMeta: catalogObjectMeta(catalog, bundleName),
 			Spec: v1alpha1.BundleMetadataSpec{
-				Catalog: corev1.LocalObjectReference{Name: catalog.Name},
-				Package: bundle.Package,
-				Image:   bundle.Image,
+				Catalog:     corev1.LocalObjectReference{Name: catalog.Name},
+				Name:        bundle.Name,
+				Package:     bundle.Package,
+				Image:       bundle.Image,
+				Deprecation: deprecations[bundle.Package].bundleDeprecation(bundle.Name),
 			},
+			Status: bundleStatus(bundle, channels[bundle.Package][bundle.Name]),
 		}
 
 		for _, relatedImage := range bundle.RelatedImages {
@@ -237,9 +568,23 @@ func (r *CatalogReconciler) syncBundleMetadata(ctx context.Context, declCfg *dec
 		}
 
 		for _, prop := range bundle.Properties {
-			// skip any properties that are of type `olm.bundle.object`
-			if prop.Type == "olm.bundle.object" {
-				continue
+			if prop.Type == property.TypeBundleObject {
+				// skip any properties that are of type `olm.bundle.object`
+				// unless the catalog preserves them
+				if catalog.Spec.BundleObjects == nil {
+					continue
+				}
+				// keep small properties inline and store larger ones in
+				// BundleObjects referenced from the bundle metadata
+				if len(prop.Value) > maxInlineBundleObjectSize(catalog) {
+					bundleObject, err := newBundleObject(catalog, prop.Value)
+					if err != nil {
+						return 0, fmt.Errorf("bundle %q: %v", bundle.Name, err)
+					}
+					newBundleObjects[bundleObject.Name] = bundleObject
+					bundleMeta.Spec.BundleObjectRefs = append(bundleMeta.Spec.BundleObjectRefs, corev1.LocalObjectReference{Name: bundleObject.Name})
+					continue
+				}
 			}
 
 			bundleMeta.Spec.Properties = append(bundleMeta.Spec.Properties, v1alpha1.Property{
@@ -250,15 +595,22 @@ func (r *CatalogReconciler) syncBundleMetadata(ctx context.Context, declCfg *dec
 		newBundles[bundleName] = &bundleMeta
 	}
 
+	// sync the bundle objects before the bundle metadata that reference them
+	if err := r.syncBundleObjects(ctx, newBundleObjects, catalog); err != nil {
+		return 0, err
+	}
+
 	var existingBundles v1alpha1.BundleMetadataList
-	if err := r.List(ctx, &existingBundles); err != nil {
-		return fmt.Errorf("list existing bundle metadatas: %v", err)
+	if err := r.List(ctx, &existingBundles, client.MatchingLabels{"catalog": catalog.Name}); err != nil {
+		return 0, fmt.Errorf("list existing bundle metadatas: %v", err)
 	}
+	pruned := 0
 	for _, existingBundle := range existingBundles.Items {
 		if _, ok := newBundles[existingBundle.Name]; !ok {
 			if err := r.Delete(ctx, &existingBundle); err != nil {
-				return fmt.Errorf("delete existing bundle metadata %q: %v", existingBundle.Name, err)
+				return 0, fmt.Errorf("delete existing bundle metadata %q: %v", existingBundle.Name, err)
 			}
+			pruned++
 		}
 	}
 
@@ -266,21 +618,159 @@ func (r *CatalogReconciler) syncBundleMetadata(ctx context.Context, declCfg *dec
 	for _, bundleName := range ordered {
 		newBundle := newBundles[bundleName]
 		if err := r.Client.Patch(ctx, newBundle, client.Apply, &client.PatchOptions{Force: pointer.Bool(true), FieldManager: "catalog-controller"}); err != nil {
-			return fmt.Errorf("applying bundle metadata %q: %w", newBundle.Name, err)
+			return 0, fmt.Errorf("applying bundle metadata %q: %w", newBundle.Name, err)
+		}
+	}
+	metrics.BundleMetadata.WithLabelValues(catalog.Name).Set(float64(len(newBundles)))
+	return pruned, nil
+}
+
+// syncBundleObjects creates the given BundleObjects and deletes the
+// BundleObjects of the catalog that are no longer referenced by any of its
+// bundles.
+func (r *CatalogReconciler) syncBundleObjects(ctx context.Context, newBundleObjects map[string]*v1alpha1.BundleObject, catalog *v1alpha1.Catalog) error {
+	var existingBundleObjects v1alpha1.BundleObjectList
+	if err := r.List(ctx, &existingBundleObjects, client.MatchingLabels{"catalog": catalog.Name}); err != nil {
+		return fmt.Errorf("list existing bundle objects: %v", err)
+	}
+	for _, existingBundleObject := range existingBundleObjects.Items {
+		if _, ok := newBundleObjects[existingBundleObject.Name]; !ok {
+			if err := r.Delete(ctx, &existingBundleObject); err != nil {
+				return fmt.Errorf("delete existing bundle object %q: %v", existingBundleObject.Name, err)
+			}
+		}
+	}
+
+	for _, name := range sets.List(sets.KeySet(newBundleObjects)) {
+		newBundleObject := newBundleObjects[name]
+		if err := r.Client.Patch(ctx, newBundleObject, client.Apply, &client.PatchOptions{Force: pointer.Bool(true), FieldManager: "catalog-controller"}); err != nil {
+			return fmt.Errorf("applying bundle object %q: %w", newBundleObject.Name, err)
 		}
 	}
 	return nil
 }
 
+// maxInlineBundleObjectSize returns the maximum size of an olm.bundle.object
+// property value that is kept inline in the BundleMetadata of the catalog.
+func maxInlineBundleObjectSize(catalog *v1alpha1.Catalog) int {
+	if catalog.Spec.BundleObjects == nil || catalog.Spec.BundleObjects.MaxInlineSize == nil {
+		return defaultMaxInlineBundleObjectSize
+	}
+	return int(*catalog.Spec.BundleObjects.MaxInlineSize)
+}
+
+// catalogObjectMeta returns the metadata of an object with the given name
+// that is created from the contents of the catalog, labelled with and owned by
+// the catalog so that it is listed and garbage collected along with it.
+func catalogObjectMeta(catalog *v1alpha1.Catalog, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name: name,
+		Labels: map[string]string{
+			"catalog": catalog.Name,
+		},
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion:         v1alpha1.GroupVersion.String(),
+			Kind:               "Catalog",
+			Name:               catalog.Name,
+			UID:                catalog.UID,
+			BlockOwnerDeletion: pointer.Bool(true),
+			Controller:         pointer.Bool(true),
+		}},
+	}
+}
+
+// newBundleObject returns the BundleObject holding the data of the given
+// olm.bundle.object property value, named after the digest of the data. An
+// error naming the object is returned when the data is too large to be stored.
+func newBundleObject(catalog *v1alpha1.Catalog, value json.RawMessage) (*v1alpha1.BundleObject, error) {
+	var obj struct {
+		Data []byte `json:"data"`
+	}
+	if err := json.Unmarshal(value, &obj); err != nil {
+		return nil, fmt.Errorf("parse %q property: %v", property.TypeBundleObject, err)
+	}
+	if len(obj.Data) > maxBundleObjectDataSize {
+		var manifest metav1.PartialObjectMetadata
+		if err := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(obj.Data), 4096).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("%q property of %d bytes exceeds the maximum BundleObject size of %d bytes", property.TypeBundleObject, len(obj.Data), maxBundleObjectDataSize)
+		}
+		return nil, fmt.Errorf("%q property for %s %q of %d bytes exceeds the maximum BundleObject size of %d bytes", property.TypeBundleObject, manifest.Kind, manifest.Name, len(obj.Data), maxBundleObjectDataSize)
+	}
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(obj.Data))
+	return &v1alpha1.BundleObject{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha1.GroupVersion.String(),
+			Kind:       "BundleObject",
+		},
+		ObjectMeta: catalogObjectMeta(catalog, names.ForCatalogObject(catalog.Name, digest)),
+		Spec: v1alpha1.BundleObjectSpec{
+			Catalog: corev1.LocalObjectReference{Name: catalog.Name},
+			Digest:  digest,
+			Data:    obj.Data,
+		},
+	}, nil
+}
+
+// bundleChannels returns the names of the channels that contain each bundle,
+// keyed by package name and then by bundle name.
+func bundleChannels(channels []declcfg.Channel) map[string]map[string][]string {
+	bundleChannels := map[string]map[string][]string{}
+	for _, ch := range channels {
+		if bundleChannels[ch.Package] == nil {
+			bundleChannels[ch.Package] = map[string][]string{}
+		}
+		for _, entry := range ch.Entries {
+			bundleChannels[ch.Package][entry.Name] = append(bundleChannels[ch.Package][entry.Name], ch.Name)
+		}
+	}
+	return bundleChannels
+}
+
+// bundleStatus parses the well-known properties of a bundle into a
+// BundleMetadataStatus. Properties that cannot be parsed are left out of the
+// status, since they are still available verbatim in the spec.
+func bundleStatus(bundle declcfg.Bundle, channels []string) v1alpha1.BundleMetadataStatus {
+	status := v1alpha1.BundleMetadataStatus{Channels: sets.List(sets.New(channels...))}
+
+	props, err := property.Parse(bundle.Properties)
+	if err != nil {
+		return status
+	}
+	if len(props.Packages) == 1 {
+		status.Version = props.Packages[0].Version
+	}
+	for _, gvk := range props.GVKs {
+		status.ProvidedGVKs = append(status.ProvidedGVKs, v1alpha1.GroupVersionKind{
+			Group:   gvk.Group,
+			Version: gvk.Version,
+			Kind:    gvk.Kind,
+		})
+	}
+	for _, required := range props.PackagesRequired {
+		status.RequiredPackages = append(status.RequiredPackages, v1alpha1.RequiredPackage{
+			PackageName:  required.PackageName,
+			VersionRange: required.VersionRange,
+		})
+	}
+	return status
+}
+
 // syncPackages will create a `Package` resource for each
 // "olm.package" object that exists for the given catalog contents.
 // `Package.Spec.Channels` is populated by filtering all "olm.channel" objects
-// where the "packageName" == `Package.Name`. Returns an error if any are encountered.
-func (r *CatalogReconciler) syncPackages(ctx context.Context, declCfg *declcfg.DeclarativeConfig, catalog *v1alpha1.Catalog) error {
+// where the "packageName" == `Package.Name`. Existing `Package` resources of the
+// same catalog that are no longer present in its contents are deleted. Returns
+// the number of deleted resources, or an error if any are encountered.
+func (r *CatalogReconciler) syncPackages(ctx context.Context, declCfg *declcfg.DeclarativeConfig, catalog *v1alpha1.Catalog) (int, error) {
+	defer observeSyncDuration(metrics.ResourcePackages, time.Now())
 	newPkgs := map[string]*v1alpha1.Package{}
+	deprecations, err := parseDeprecations(declCfg)
+	if err != nil {
+		return 0, err
+	}
 
 	for _, pkg := range declCfg.Packages {
-		name := fmt.Sprintf("%s-%s", catalog.Name, pkg.Name)
+		name := names.ForCatalogObject(catalog.Name, pkg.Name)
 		var icon *v1alpha1.Icon
 		if pkg.Icon != nil {
 			icon = &v1alpha1.Icon{
@@ -293,20 +783,7 @@ func (r *CatalogReconciler) syncPackages(ctx context.Context, declCfg *declcfg.D
 				APIVersion: v1alpha1.GroupVersion.String(),
 				Kind:       "Package",
 			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name: name,
-				Labels: map[string]string{
-					"catalog": catalog.Name,
-				},
-				OwnerReferences: []metav1.OwnerReference{{
-					APIVersion:         v1alpha1.GroupVersion.String(),
-					Kind:               "Catalog",
-					Name:               catalog.Name,
-					UID:                catalog.UID,
-					BlockOwnerDeletion: pointer.Bool(true),
-					Controller:         pointer.Bool(true),
-				}},
-			},
+			ObjectMeta: catalogObjectMeta(catalog, name),
 			Spec: v1alpha1.PackageSpec{
 				Catalog:        corev1.LocalObjectReference{Name: catalog.Name},
 				Name:           pkg.Name,
@@ -314,17 +791,23 @@ func (r *CatalogReconciler) syncPackages(ctx context.Context, declCfg *declcfg.D
 				Description:    pkg.Description,
 				Icon:           icon,
 				Channels:       []v1alpha1.PackageChannel{},
+				Deprecation:    deprecations[pkg.Name].packageDeprecation(),
 			},
 		}
 	}
 
+	versions := bundleVersions(declCfg.Bundles)
+
 	for _, ch := range declCfg.Channels {
-		pkgName := fmt.Sprintf("%s-%s", catalog.Name, ch.Package)
+		pkgName := names.ForCatalogObject(catalog.Name, ch.Package)
 		pkg, ok := newPkgs[pkgName]
 		if !ok {
-			return fmt.Errorf("channel %q references package %q which does not exist", ch.Name, ch.Package)
+			return 0, fmt.Errorf("channel %q references package %q which does not exist", ch.Name, ch.Package)
+		}
+		pkgChannel := v1alpha1.PackageChannel{
+			Name:        ch.Name,
+			Deprecation: deprecations[ch.Package].channelDeprecation(ch.Name),
 		}
-		pkgChannel := v1alpha1.PackageChannel{Name: ch.Name}
 		for _, entry := range ch.Entries {
 			pkgChannel.Entries = append(pkgChannel.Entries, v1alpha1.ChannelEntry{
 				Name:      entry.Name,
@@ -334,18 +817,21 @@ func (r *CatalogReconciler) syncPackages(ctx context.Context, declCfg *declcfg.D
 			})
 		}
 		pkg.Spec.Channels = append(pkg.Spec.Channels, pkgChannel)
+		pkg.Status.Channels = append(pkg.Status.Channels, channelStatus(ch, versions[ch.Package]))
 	}
 
 	var existingPkgs v1alpha1.PackageList
-	if err := r.List(ctx, &existingPkgs); err != nil {
-		return fmt.Errorf("list existing packages: %v", err)
+	if err := r.List(ctx, &existingPkgs, client.MatchingLabels{"catalog": catalog.Name}); err != nil {
+		return 0, fmt.Errorf("list existing packages: %v", err)
 	}
+	pruned := 0
 	for _, existingPkg := range existingPkgs.Items {
 		if _, ok := newPkgs[existingPkg.Name]; !ok {
 			// delete existing package
 			if err := r.Delete(ctx, &existingPkg); err != nil {
-				return fmt.Errorf("delete existing package %q: %v", existingPkg.Name, err)
+				return 0, fmt.Errorf("delete existing package %q: %v", existingPkg.Name, err)
 			}
+			pruned++
 		}
 	}
 
@@ -353,8 +839,210 @@ func (r *CatalogReconciler) syncPackages(ctx context.Context, declCfg *declcfg.D
 	for _, pkgName := range ordered {
 		newPkg := newPkgs[pkgName]
 		if err := r.Client.Patch(ctx, newPkg, client.Apply, &client.PatchOptions{Force: pointer.Bool(true), FieldManager: "catalog-controller"}); err != nil {
-			return fmt.Errorf("applying package %q: %w", newPkg.Name, err)
+			return 0, fmt.Errorf("applying package %q: %w", newPkg.Name, err)
+		}
+	}
+	metrics.Packages.WithLabelValues(catalog.Name).Set(float64(len(newPkgs)))
+
+	if err := r.syncPackagePrecedence(ctx, catalog, newPkgs); err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+// syncPackagePrecedence sets the preferred catalog in the status of every
+// Package to the Catalog whose package of the same name is preferred. Since
+// the existing Packages of the reconciled catalog may not yet be up to date
+// in the cache, its applied Packages are given explicitly; catalog is nil when
+// the reconciled catalog no longer exists.
+//
+// The preferred catalog is applied by its own field manager so that it is
+// left untouched when the Packages of each catalog are applied.
+func (r *CatalogReconciler) syncPackagePrecedence(ctx context.Context, catalog *v1alpha1.Catalog, catalogPkgs map[string]*v1alpha1.Package) error {
+	var catalogs v1alpha1.CatalogList
+	if err := r.List(ctx, &catalogs); err != nil {
+		return fmt.Errorf("list catalogs: %v", err)
+	}
+	priorities := map[string]int32{}
+	for _, c := range catalogs.Items {
+		if c.DeletionTimestamp.IsZero() {
+			priorities[c.Name] = c.Spec.Priority
+		}
+	}
+
+	var existingPkgs v1alpha1.PackageList
+	if err := r.List(ctx, &existingPkgs); err != nil {
+		return fmt.Errorf("list packages: %v", err)
+	}
+	var pkgs []*v1alpha1.Package
+	for i := range existingPkgs.Items {
+		if catalog == nil || existingPkgs.Items[i].Spec.Catalog.Name != catalog.Name {
+			pkgs = append(pkgs, &existingPkgs.Items[i])
+		}
+	}
+	if catalog != nil {
+		priorities[catalog.Name] = catalog.Spec.Priority
+		for _, name := range sets.List(sets.KeySet(catalogPkgs)) {
+			pkgs = append(pkgs, catalogPkgs[name])
+		}
+	}
+
+	preferred := map[string]string{}
+	for _, pkg := range pkgs {
+		catalogName := pkg.Spec.Catalog.Name
+		priority, ok := priorities[catalogName]
+		if !ok {
+			continue
+		}
+		current, ok := preferred[pkg.Spec.Name]
+		if !ok || priority > priorities[current] || (priority == priorities[current] && catalogName < current) {
+			preferred[pkg.Spec.Name] = catalogName
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if _, ok := priorities[pkg.Spec.Catalog.Name]; !ok {
+			continue
+		}
+		preferredCatalog := preferred[pkg.Spec.Name]
+		if pkg.Status.PreferredCatalog == preferredCatalog {
+			continue
+		}
+		patch := &unstructured.Unstructured{}
+		patch.SetGroupVersionKind(v1alpha1.GroupVersion.WithKind("Package"))
+		patch.SetName(pkg.Name)
+		if err := unstructured.SetNestedField(patch.Object, preferredCatalog, "status", "preferredCatalog"); err != nil {
+			return err
+		}
+		if err := r.Client.Patch(ctx, patch, client.Apply, &client.PatchOptions{Force: pointer.Bool(true), FieldManager: "catalog-precedence-controller"}); err != nil {
+			return fmt.Errorf("applying preferred catalog of package %q: %w", pkg.Name, err)
 		}
 	}
 	return nil
 }
+
+// bundleVersions returns the version declared by the olm.package property of
+// each bundle, keyed by package name and then by bundle name. Bundles without
+// a parsable version are omitted, leaving them out of the latest version
+// computation rather than failing the sync.
+func bundleVersions(bundles []declcfg.Bundle) map[string]map[string]semver.Version {
+	versions := map[string]map[string]semver.Version{}
+	for _, bundle := range bundles {
+		props, err := property.Parse(bundle.Properties)
+		if err != nil || len(props.Packages) != 1 {
+			continue
+		}
+		version, err := semver.Parse(props.Packages[0].Version)
+		if err != nil {
+			continue
+		}
+		if versions[bundle.Package] == nil {
+			versions[bundle.Package] = map[string]semver.Version{}
+		}
+		versions[bundle.Package][bundle.Name] = version
+	}
+	return versions
+}
+
+// channelStatus derives the head, latest version and bundle count of a
+// channel from its entries and the versions of its package's bundles.
+func channelStatus(ch declcfg.Channel, versions map[string]semver.Version) v1alpha1.ChannelStatus {
+	status := v1alpha1.ChannelStatus{Name: ch.Name, BundleCount: len(ch.Entries)}
+
+	replaced := sets.New[string]()
+	for _, entry := range ch.Entries {
+		if entry.Replaces != "" {
+			replaced.Insert(entry.Replaces)
+		}
+		replaced.Insert(entry.Skips...)
+	}
+	var heads []string
+	var latest *semver.Version
+	for _, entry := range ch.Entries {
+		if !replaced.Has(entry.Name) {
+			heads = append(heads, entry.Name)
+		}
+		if v, ok := versions[entry.Name]; ok && (latest == nil || v.GT(*latest)) {
+			latest = &v
+		}
+	}
+	if len(heads) == 1 {
+		status.Head = heads[0]
+	}
+	if latest != nil {
+		status.LatestVersion = latest.String()
+	}
+	return status
+}
+
+// syncCatalogMetadata will create a `CatalogMetadata` resource for each blob
+// of the given catalog contents whose schema is not otherwise interpreted.
+func (r *CatalogReconciler) syncCatalogMetadata(ctx context.Context, declCfg *declcfg.DeclarativeConfig, catalog *v1alpha1.Catalog) (int, error) {
+	defer observeSyncDuration(metrics.ResourceCatalogMetadata, time.Now())
+	newMetadata := map[string]*v1alpha1.CatalogMetadata{}
+
+	for _, meta := range declCfg.Others {
+		if meta.Schema == schemaDeprecations {
+			continue
+		}
+		var blob struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(meta.Blob, &blob); err != nil {
+			return 0, fmt.Errorf("parse %q blob: %v", meta.Schema, err)
+		}
+
+		// Blobs are identified by their schema, package and name, none of
+		// which but the schema are required, so number any blobs that share
+		// all three in the order that they were loaded.
+		id := strings.Join([]string{meta.Schema, meta.Package, blob.Name}, "/")
+		name := names.ForCatalogObject(catalog.Name, id)
+		for i := 1; newMetadata[name] != nil; i++ {
+			name = names.ForCatalogObject(catalog.Name, fmt.Sprintf("%s/%d", id, i))
+		}
+
+		if len(meta.Blob) > maxCatalogMetadataContentSize {
+			return 0, fmt.Errorf("%q blob for package %q named %q of %d bytes exceeds the maximum CatalogMetadata size of %d bytes", meta.Schema, meta.Package, blob.Name, len(meta.Blob), maxCatalogMetadataContentSize)
+		}
+		newMetadata[name] = &v1alpha1.CatalogMetadata{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.GroupVersion.String(),
+				Kind:       "CatalogMetadata",
+			},
+			ObjectMeta: catalogObjectMeta(catalog, name),
+			Spec: v1alpha1.CatalogMetadataSpec{
+				Catalog: corev1.LocalObjectReference{Name: catalog.Name},
+				Schema:  meta.Schema,
+				Package: meta.Package,
+				Name:    blob.Name,
+				Content: meta.Blob,
+			},
+		}
+	}
+
+	var existingMetadata v1alpha1.CatalogMetadataList
+	if err := r.List(ctx, &existingMetadata, client.MatchingLabels{"catalog": catalog.Name}); err != nil {
+		return 0, fmt.Errorf("list existing catalog metadata: %v", err)
+	}
+	pruned := 0
+	for _, existing := range existingMetadata.Items {
+		if _, ok := newMetadata[existing.Name]; !ok {
+			if err := r.Delete(ctx, &existing); err != nil {
+				return 0, fmt.Errorf("delete existing catalog metadata %q: %v", existing.Name, err)
+			}
+			pruned++
+		}
+	}
+
+	for _, name := range sets.List(sets.KeySet(newMetadata)) {
+		newMeta := newMetadata[name]
+		if err := r.Client.Patch(ctx, newMeta, client.Apply, &client.PatchOptions{Force: pointer.Bool(true), FieldManager: "catalog-controller"}); err != nil {
+			return 0, fmt.Errorf("applying catalog metadata %q: %w", newMeta.Name, err)
+		}
+	}
+	return pruned, nil
+}
+
+func observeSyncDuration(resource string, start time.Time) {
+	metrics.SyncDuration.WithLabelValues(resource).Observe(time.Since(start).Seconds())
+}
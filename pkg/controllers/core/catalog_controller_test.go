@@ -2,23 +2,38 @@ package core_test
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"testing/fstest"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/operator-framework/catalogd/api/core/v1alpha1"
 	"github.com/operator-framework/catalogd/internal/source"
 	"github.com/operator-framework/catalogd/pkg/controllers/core"
+	"github.com/operator-framework/catalogd/pkg/metrics"
+	"github.com/operator-framework/catalogd/pkg/names"
+	"github.com/operator-framework/catalogd/pkg/storage"
 )
 
 var _ source.Unpacker = &MockSource{}
@@ -30,25 +45,41 @@ type MockSource struct {
 
 	// shouldError determines whether or not the MockSource should return an error when MockSource.Unpack is called
 	shouldError bool
+
+	// err is the error that MockSource.Unpack returns when shouldError is set, if not nil
+	err error
+
+	// cleanedUp holds the names of the catalogs that MockSource.Cleanup was called for
+	cleanedUp []string
 }
 
 func (ms *MockSource) Unpack(ctx context.Context, catalog *v1alpha1.Catalog) (*source.Result, error) {
 	if ms.shouldError {
+		if ms.err != nil {
+			return nil, ms.err
+		}
 		return nil, errors.New("mocksource error")
 	}
 
 	return ms.result, nil
 }
 
+func (ms *MockSource) Cleanup(ctx context.Context, catalog *v1alpha1.Catalog) error {
+	ms.cleanedUp = append(ms.cleanedUp, catalog.Name)
+	return nil
+}
+
 var _ = Describe("Catalogd Controller Test", func() {
 	var (
 		ctx        context.Context
 		reconciler *core.CatalogReconciler
 		mockSource *MockSource
+		recorder   *record.FakeRecorder
 	)
 	BeforeEach(func() {
 		ctx = context.Background()
 		mockSource = &MockSource{}
+		recorder = record.NewFakeRecorder(100)
 		reconciler = &core.CatalogReconciler{
 			Client: cl,
 			Unpacker: source.NewUnpacker(
@@ -56,9 +87,23 @@ var _ = Describe("Catalogd Controller Test", func() {
 					v1alpha1.SourceTypeImage: mockSource,
 				},
 			),
+			Recorder: recorder,
 		}
 	})
 
+	// recordedEvents drains and returns the events recorded by the reconciler.
+	recordedEvents := func() []string {
+		var events []string
+		for {
+			select {
+			case event := <-recorder.Events:
+				events = append(events, event)
+			default:
+				return events
+			}
+		}
+	}
+
 	When("the catalog does not exist", func() {
 		It("returns no error", func() {
 			res, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "non-existent"}})
@@ -122,6 +167,7 @@ var _ = Describe("Catalogd Controller Test", func() {
 				Expect(cond).ToNot(BeNil())
 				Expect(cond.Reason).To(Equal(v1alpha1.ReasonUnpackFailed))
 				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(recordedEvents()).To(ConsistOf(HavePrefix("Warning UnpackFailed source bundle content: source type \"invalid-source\" not supported")))
 			})
 		})
 
@@ -167,6 +213,13 @@ var _ = Describe("Catalogd Controller Test", func() {
 					Expect(cond).ToNot(BeNil())
 					Expect(cond.Reason).To(Equal(v1alpha1.ReasonUnpackPending))
 					Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+					Expect(recordedEvents()).To(ConsistOf("Normal UnpackStarted started unpacking the catalog from its image source"))
+
+					By("not recording the start again while the unpack is in progress")
+					mockSource.result = &source.Result{State: source.StateUnpacking}
+					_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(recordedEvents()).To(BeEmpty())
 				})
 			})
 
@@ -193,6 +246,101 @@ var _ = Describe("Catalogd Controller Test", func() {
 				})
 			})
 
+			When("unpacker returns source.Result with a deadline", func() {
+				BeforeEach(func() {
+					mockSource.shouldError = false
+					mockSource.result = &source.Result{State: source.StateUnpacking, Deadline: time.Now().Add(5 * time.Minute)}
+				})
+
+				It("should requeue the catalog by the deadline", func() {
+					res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+					Expect(res.RequeueAfter).To(BeNumerically("<=", 5*time.Minute))
+				})
+			})
+
+			When("unpacker times out", func() {
+				BeforeEach(func() {
+					mockSource.shouldError = true
+					mockSource.err = &source.UnpackTimeoutError{Timeout: 10 * time.Minute, Message: "Back-off pulling image"}
+				})
+
+				It("should set unpacking status to failed with the UnpackTimeout reason and return an error", func() {
+					_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).To(HaveOccurred())
+
+					cat := &v1alpha1.Catalog{}
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseFailing))
+					cond := meta.FindStatusCondition(cat.Status.Conditions, v1alpha1.TypeUnpacked)
+					Expect(cond).ToNot(BeNil())
+					Expect(cond.Reason).To(Equal(v1alpha1.ReasonUnpackTimeout))
+					Expect(cond.Message).To(Equal("catalog was not unpacked within 10m0s: Back-off pulling image"))
+					Expect(recordedEvents()).To(ConsistOf(HavePrefix("Warning UnpackTimeout")))
+				})
+			})
+
+			When("unpacking fails repeatedly", func() {
+				BeforeEach(func() {
+					reconciler.MaxUnpackBackoff = time.Minute
+					mockSource.shouldError = true
+				})
+
+				It("should count the failed attempts and back off before retrying", func() {
+					res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(res.RequeueAfter).To(Equal(10 * time.Second))
+					cat := &v1alpha1.Catalog{}
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.UnpackAttempts).To(Equal(int32(1)))
+					Expect(cat.Status.LastUnpackAttemptTime).ToNot(BeNil())
+
+					By("not retrying until the backoff has passed")
+					res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+					Expect(res.RequeueAfter).To(BeNumerically("<=", 10*time.Second))
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.UnpackAttempts).To(Equal(int32(1)))
+
+					By("retrying right away once the spec changes")
+					cat.Spec.Priority = 1
+					Expect(cl.Update(ctx, cat)).To(Succeed())
+					res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(res.RequeueAfter).To(Equal(20 * time.Second))
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.UnpackAttempts).To(Equal(int32(2)))
+					Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseFailing))
+
+					By("resetting the attempts once unpacked")
+					cat.Spec.Priority = 2
+					Expect(cl.Update(ctx, cat)).To(Succeed())
+					mockSource.shouldError = false
+					mockSource.result = &source.Result{ResolvedSource: &catalog.Spec.Source, State: source.StateUnpacked, FS: &fstest.MapFS{}}
+					_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.UnpackAttempts).To(BeZero())
+					Expect(cat.Status.LastUnpackAttemptTime).To(BeNil())
+				})
+
+				It("should not unpack the catalog while it is suspended", func() {
+					cat := &v1alpha1.Catalog{}
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					cat.Spec.Suspend = true
+					Expect(cl.Update(ctx, cat)).To(Succeed())
+
+					res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(res).To(Equal(ctrl.Result{}))
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.Phase).To(BeEmpty())
+					Expect(cat.Status.UnpackAttempts).To(BeZero())
+				})
+			})
+
 			When("unpacker returns source.Result with unknown state", func() {
 				BeforeEach(func() {
 					mockSource.shouldError = false
@@ -234,8 +382,8 @@ var _ = Describe("Catalogd Controller Test", func() {
 					testPackageMetaName string
 				)
 				BeforeEach(func() {
-					testBundleMetaName = fmt.Sprintf("%s-%s", catalog.Name, testBundleName)
-					testPackageMetaName = fmt.Sprintf("%s-%s", catalog.Name, testPackageName)
+					testBundleMetaName = names.ForCatalogObject(catalog.Name, testBundleName)
+					testPackageMetaName = names.ForCatalogObject(catalog.Name, testPackageName)
 
 					filesys := &fstest.MapFS{
 						"bundle.yaml":  &fstest.MapFile{Data: []byte(testBundle), Mode: os.ModePerm},
@@ -248,6 +396,7 @@ var _ = Describe("Catalogd Controller Test", func() {
 						ResolvedSource: &catalog.Spec.Source,
 						State:          source.StateUnpacked,
 						FS:             filesys,
+						Message:        "successfully unpacked the catalog",
 					}
 
 					// reconcile
@@ -295,6 +444,7 @@ var _ = Describe("Catalogd Controller Test", func() {
 					Expect(bundlemetadata.Name).To(Equal(testBundleMetaName))
 					Expect(bundlemetadata.Spec.Image).To(Equal(testBundleImage))
 					Expect(bundlemetadata.Spec.Catalog.Name).To(Equal(catalog.Name))
+					Expect(bundlemetadata.Spec.Name).To(Equal(testBundleName))
 					Expect(bundlemetadata.Spec.Package).To(Equal(testPackageName))
 					Expect(bundlemetadata.Spec.RelatedImages).To(HaveLen(1))
 					Expect(bundlemetadata.Spec.RelatedImages[0].Name).To(Equal(testBundleRelatedImageName))
@@ -316,9 +466,750 @@ var _ = Describe("Catalogd Controller Test", func() {
 					Expect(pack.Spec.Channels[0].Entries).To(HaveLen(1))
 					Expect(Expect(pack.Spec.Channels[0].Entries[0].Name).To(Equal(testBundleName)))
 				})
+
+				It("should record events for the start and success of the unpack", func() {
+					Expect(recordedEvents()).To(ContainElements(
+						"Normal UnpackStarted started unpacking the catalog from its image source",
+						"Normal UnpackSuccessful successfully unpacked the catalog",
+					))
+				})
+
+				It("should record the catalog metrics", func() {
+					Expect(testutil.ToFloat64(metrics.Packages.WithLabelValues(catalog.Name))).To(Equal(1.0))
+					Expect(testutil.ToFloat64(metrics.BundleMetadata.WithLabelValues(catalog.Name))).To(Equal(1.0))
+					Expect(testutil.ToFloat64(metrics.ContentSize.WithLabelValues(catalog.Name))).To(BeNumerically("==", len(testBundle)+len(testPackage)+len(testChannel)))
+				})
+
+				It("should preserve bundle objects when the catalog opts in", func() {
+					updateCatalog := func(bundleObjects *v1alpha1.BundleObjectsConfig) {
+						cat := &v1alpha1.Catalog{}
+						Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+						cat.Spec.BundleObjects = bundleObjects
+						Expect(cl.Update(ctx, cat)).To(Succeed())
+						_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+						Expect(err).ToNot(HaveOccurred())
+					}
+					bundlemetadata := &v1alpha1.BundleMetadata{}
+
+					By("keeping small bundle objects inline")
+					updateCatalog(&v1alpha1.BundleObjectsConfig{})
+					Expect(cl.Get(ctx, types.NamespacedName{Name: testBundleMetaName}, bundlemetadata)).To(Succeed())
+					Expect(bundlemetadata.Spec.Properties).To(HaveLen(2))
+					Expect(bundlemetadata.Spec.Properties).To(ContainElement(HaveField("Type", "olm.bundle.object")))
+					Expect(bundlemetadata.Spec.BundleObjectRefs).To(BeEmpty())
+
+					By("storing larger bundle objects separately")
+					updateCatalog(&v1alpha1.BundleObjectsConfig{MaxInlineSize: pointer.Int32(0)})
+					Expect(cl.Get(ctx, types.NamespacedName{Name: testBundleMetaName}, bundlemetadata)).To(Succeed())
+					Expect(bundlemetadata.Spec.Properties).To(HaveLen(1))
+					Expect(bundlemetadata.Spec.BundleObjectRefs).To(HaveLen(1))
+					bundleObject := &v1alpha1.BundleObject{}
+					Expect(cl.Get(ctx, types.NamespacedName{Name: bundlemetadata.Spec.BundleObjectRefs[0].Name}, bundleObject)).To(Succeed())
+					Expect(bundleObject.Spec.Catalog.Name).To(Equal(catalog.Name))
+					Expect(bundleObject.Spec.Digest).To(HavePrefix("sha256:"))
+					Expect(string(bundleObject.Spec.Data)).To(Equal("unimportant\n"))
+
+					By("dropping bundle objects once the catalog opts out")
+					updateCatalog(nil)
+					Expect(cl.Get(ctx, types.NamespacedName{Name: testBundleMetaName}, bundlemetadata)).To(Succeed())
+					Expect(bundlemetadata.Spec.Properties).To(HaveLen(1))
+					Expect(bundlemetadata.Spec.BundleObjectRefs).To(BeEmpty())
+					bundleObjects := &v1alpha1.BundleObjectList{}
+					Expect(cl.List(ctx, bundleObjects)).To(Succeed())
+					Expect(bundleObjects.Items).To(BeEmpty())
+				})
+
+				It("should fail to preserve bundle objects that are too large to store", func() {
+					manifest := fmt.Sprintf(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"huge"},"data":{"blob":%q}}`, strings.Repeat("x", 1<<20))
+					largeBundle := fmt.Sprintf(testBundleTemplate, testBundleImage, testBundleName, testPackageName, testBundleRelatedImageName, testBundleRelatedImageImage, base64.StdEncoding.EncodeToString([]byte(manifest)))
+					mockSource.result.FS = &fstest.MapFS{
+						"bundle.yaml":  &fstest.MapFile{Data: []byte(largeBundle), Mode: os.ModePerm},
+						"package.yaml": &fstest.MapFile{Data: []byte(testPackage), Mode: os.ModePerm},
+						"channel.yaml": &fstest.MapFile{Data: []byte(testChannel), Mode: os.ModePerm},
+					}
+					cat := &v1alpha1.Catalog{}
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					cat.Spec.BundleObjects = &v1alpha1.BundleObjectsConfig{MaxInlineSize: pointer.Int32(0)}
+					Expect(cl.Update(ctx, cat)).To(Succeed())
+
+					_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("bundle %q", testBundleName))))
+					Expect(err).To(MatchError(ContainSubstring(`ConfigMap "huge"`)))
+					Expect(err).To(MatchError(ContainSubstring("exceeds the maximum BundleObject size")))
+
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseFailing))
+				})
+
+				It("should only sync again when the unpacked contents change", func() {
+					cat := &v1alpha1.Catalog{}
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.ContentDigest).ToNot(BeEmpty())
+					digest := cat.Status.ContentDigest
+					unpacks := unpackDurationSamples(cat.Spec.Source.Type)
+
+					By("deleting the package and reconciling unchanged contents")
+					Expect(cl.Delete(ctx, &v1alpha1.Package{ObjectMeta: metav1.ObjectMeta{Name: testPackageMetaName}})).To(Succeed())
+					_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cl.Get(ctx, types.NamespacedName{Name: testPackageMetaName}, &v1alpha1.Package{})).ToNot(Succeed())
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.ContentDigest).To(Equal(digest))
+					Expect(unpackDurationSamples(cat.Spec.Source.Type)).To(Equal(unpacks))
+
+					By("reconciling changed contents")
+					filesys := mockSource.result.FS.(*fstest.MapFS)
+					(*filesys)["package.yaml"] = &fstest.MapFile{Data: []byte(testPackage + "description: changed\n"), Mode: os.ModePerm}
+					_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cl.Get(ctx, types.NamespacedName{Name: testPackageMetaName}, &v1alpha1.Package{})).To(Succeed())
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.ContentDigest).ToNot(Equal(digest))
+					Expect(unpackDurationSamples(cat.Spec.Source.Type)).To(Equal(unpacks + 1))
+				})
+			})
+		})
+
+		When("the catalog is sourced from configmaps", func() {
+			var configMap *corev1.ConfigMap
+
+			BeforeEach(func() {
+				By("initializing cluster state")
+				configMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name, Namespace: "default"},
+					Data: map[string]string{
+						"package.yaml": fmt.Sprintf(testPackageTemplate, "stable", "cm-operator"),
+						"channel.yaml": fmt.Sprintf(testChannelTemplate, "cm-operator", "stable", "cm-operator.v1.0.0"),
+						"bundle.yaml":  fmt.Sprintf(testBundleTemplate, "quay.io/cm/bundle:v1.0.0", "cm-operator.v1.0.0", "cm-operator", "test", "testimage:latest", "dW5pbXBvcnRhbnQK"),
+					},
+				}
+				Expect(cl.Create(ctx, configMap)).To(Succeed())
+
+				catalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type: v1alpha1.SourceTypeConfigMaps,
+							ConfigMaps: []v1alpha1.ConfigMapSource{
+								{ConfigMap: corev1.LocalObjectReference{Name: configMap.Name}},
+							},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, catalog)).To(Succeed())
+
+				reconciler.Unpacker = source.NewUnpacker(map[v1alpha1.SourceType]source.Unpacker{
+					v1alpha1.SourceTypeConfigMaps: &source.ConfigMaps{Reader: cl, ConfigMapNamespace: configMap.Namespace},
+				})
+			})
+
+			AfterEach(func() {
+				By("tearing down cluster state")
+				Expect(cl.Delete(ctx, catalog)).To(Succeed())
+				Expect(cl.Delete(ctx, configMap)).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.Package{})).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.BundleMetadata{})).To(Succeed())
+			})
+
+			It("should unpack the catalog contents without an unpack pod", func() {
+				res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(res).To(Equal(ctrl.Result{}))
+				Expect(err).ToNot(HaveOccurred())
+
+				cat := &v1alpha1.Catalog{}
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseUnpacked))
+				Expect(cat.Status.ResolvedSource).ToNot(BeNil())
+				Expect(cat.Status.ResolvedSource.ConfigMaps).To(Equal(catalog.Spec.Source.ConfigMaps))
+
+				pods := &corev1.PodList{}
+				Expect(cl.List(ctx, pods)).To(Succeed())
+				Expect(pods.Items).To(BeEmpty())
+
+				packages := &v1alpha1.PackageList{}
+				Expect(cl.List(ctx, packages)).To(Succeed())
+				Expect(packages.Items).To(HaveLen(1))
+				Expect(packages.Items[0].Spec.Name).To(Equal("cm-operator"))
+
+				bundlemetadatas := &v1alpha1.BundleMetadataList{}
+				Expect(cl.List(ctx, bundlemetadatas)).To(Succeed())
+				Expect(bundlemetadatas.Items).To(HaveLen(1))
+				Expect(bundlemetadatas.Items[0].Spec.Image).To(Equal("quay.io/cm/bundle:v1.0.0"))
+			})
+		})
+
+		When("the catalog polls its image source", func() {
+			BeforeEach(func() {
+				By("initializing cluster state")
+				catalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type: "image",
+							Image: &v1alpha1.ImageSource{
+								Ref:          "somecatalog:latest",
+								PollInterval: &metav1.Duration{Duration: 10 * time.Minute},
+							},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, catalog)).To(Succeed())
+
+				mockSource.shouldError = false
+				mockSource.result = &source.Result{
+					ResolvedSource: &catalog.Spec.Source,
+					State:          source.StateUnpacked,
+					FS:             &fstest.MapFS{},
+				}
+			})
+
+			AfterEach(func() {
+				By("tearing down cluster state")
+				Expect(cl.Delete(ctx, catalog)).NotTo(HaveOccurred())
+			})
+
+			It("should requeue the catalog after the poll interval once unpacked", func() {
+				res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(ctrl.Result{RequeueAfter: 10 * time.Minute}))
+			})
+		})
+
+		When("the catalog reports drift from its image source", func() {
+			const unpackedDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+
+			BeforeEach(func() {
+				By("initializing cluster state")
+				catalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type: "image",
+							Image: &v1alpha1.ImageSource{
+								Ref:          "somecatalog:latest",
+								DigestPolicy: v1alpha1.DigestPolicyReportDrift,
+							},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, catalog)).To(Succeed())
+
+				mockSource.shouldError = false
+				mockSource.result = &source.Result{
+					ResolvedSource: &v1alpha1.CatalogSource{
+						Type:  v1alpha1.SourceTypeImage,
+						Image: &v1alpha1.ImageSource{Ref: "somecatalog@" + unpackedDigest},
+					},
+					State:        source.StateUnpacked,
+					FS:           &fstest.MapFS{},
+					LatestDigest: unpackedDigest,
+				}
+			})
+
+			AfterEach(func() {
+				By("tearing down cluster state")
+				Expect(cl.Delete(ctx, catalog)).NotTo(HaveOccurred())
+			})
+
+			It("should set the SourceDrifted condition when the reference points to another digest", func() {
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				cat := &v1alpha1.Catalog{}
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Status.LatestImageDigest).To(Equal(unpackedDigest))
+				Expect(meta.IsStatusConditionFalse(cat.Status.Conditions, v1alpha1.TypeSourceDrifted)).To(BeTrue())
+
+				latestDigest := "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+				mockSource.result.LatestDigest = latestDigest
+				_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Status.LatestImageDigest).To(Equal(latestDigest))
+				Expect(cat.Status.ResolvedSource.Image.Ref).To(Equal("somecatalog@" + unpackedDigest))
+				cond := meta.FindStatusCondition(cat.Status.Conditions, v1alpha1.TypeSourceDrifted)
+				Expect(cond).ToNot(BeNil())
+				Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+				Expect(cond.Reason).To(Equal(v1alpha1.ReasonDigestChanged))
+				Expect(recordedEvents()).To(ContainElement(ContainSubstring(v1alpha1.ReasonDigestChanged)))
+			})
+
+			It("should keep the pinned digest when a retry follows a failed unpack", func() {
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+
+				mockSource.shouldError = true
+				_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).To(HaveOccurred())
+				cat := &v1alpha1.Catalog{}
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseFailing))
+				Expect(cat.Status.ResolvedSource).To(BeNil())
+				Expect(cat.Status.PinnedImage).To(Equal(&v1alpha1.PinnedImage{Ref: "somecatalog:latest", Digest: unpackedDigest}))
+
+				mockSource.shouldError = false
+				_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseUnpacked))
+				Expect(cat.Status.PinnedImage).To(Equal(&v1alpha1.PinnedImage{Ref: "somecatalog:latest", Digest: unpackedDigest}))
+			})
+
+			It("should keep the pinned digest while the catalog is pending across reconciles", func() {
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+
+				mockSource.result = &source.Result{State: source.StatePending}
+				cat := &v1alpha1.Catalog{}
+				for i := 0; i < 2; i++ {
+					_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					Expect(cat.Status.Phase).To(Equal(v1alpha1.PhasePending))
+					Expect(cat.Status.ResolvedSource).To(BeNil())
+					Expect(cat.Status.PinnedImage).To(Equal(&v1alpha1.PinnedImage{Ref: "somecatalog:latest", Digest: unpackedDigest}))
+				}
+			})
+		})
+
+		When("the catalog contents are stored", func() {
+			var store *storage.LocalDir
+
+			BeforeEach(func() {
+				By("initializing cluster state")
+				catalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type:  "image",
+							Image: &v1alpha1.ImageSource{Ref: "somecatalog:latest"},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, catalog)).To(Succeed())
+
+				store = &storage.LocalDir{RootDir: GinkgoT().TempDir(), BaseURL: &url.URL{Scheme: "http", Host: "catalogd-catalogserver.catalogd-system.svc"}}
+				reconciler.Storage = store
+				mockSource.shouldError = false
+				mockSource.result = &source.Result{
+					ResolvedSource: &catalog.Spec.Source,
+					State:          source.StateUnpacked,
+					FS: &fstest.MapFS{
+						"package.yaml": &fstest.MapFile{Data: []byte(fmt.Sprintf(testPackageTemplate, "stable", "stored-operator")), Mode: os.ModePerm},
+					},
+				}
+
+				By("adding the finalizer before unpacking")
+				res, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(ctrl.Result{Requeue: true}))
+
+				res, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(ctrl.Result{}))
+			})
+
+			AfterEach(func() {
+				By("tearing down cluster state")
+				Expect(client.IgnoreNotFound(cl.Delete(ctx, catalog))).To(Succeed())
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.Package{})).To(Succeed())
+			})
+
+			It("should store the contents and publish their URL", func() {
+				cat := &v1alpha1.Catalog{}
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Finalizers).To(ContainElement("catalogd.operatorframework.io/delete-server-cache"))
+				Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseUnpacked))
+				Expect(cat.Status.ContentURL).To(Equal(fmt.Sprintf("http://catalogd-catalogserver.catalogd-system.svc/catalogs/%s/all.json", cKey.Name)))
+				Expect(store.ContentExists(cKey.Name)).To(BeTrue())
+			})
+
+			It("should delete the stored contents when the catalog is deleted", func() {
+				Expect(cl.Delete(ctx, catalog)).To(Succeed())
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(store.ContentExists(cKey.Name)).To(BeFalse())
+				Expect(mockSource.cleanedUp).To(ContainElement(cKey.Name))
+				Expect(apierrors.IsNotFound(cl.Get(ctx, cKey, &v1alpha1.Catalog{}))).To(BeTrue())
+			})
+		})
+
+		When("another catalog exists alongside the catalog", func() {
+			var (
+				otherCatalog *v1alpha1.Catalog
+				otherKey     types.NamespacedName
+			)
+
+			// catalogFS returns the contents of a catalog containing a single
+			// package with one channel and one bundle per given bundle name.
+			catalogFS := func(pkgName string, bundleNames ...string) *fstest.MapFS {
+				filesys := fstest.MapFS{
+					"package.yaml": &fstest.MapFile{Data: []byte(fmt.Sprintf(testPackageTemplate, "stable", pkgName)), Mode: os.ModePerm},
+				}
+				for _, bundleName := range bundleNames {
+					filesys[bundleName+"/bundle.yaml"] = &fstest.MapFile{Data: []byte(fmt.Sprintf(testBundleTemplate, "quay.io/test/"+bundleName, bundleName, pkgName, "test", "testimage:latest", "dW5pbXBvcnRhbnQK")), Mode: os.ModePerm}
+					filesys[bundleName+"/channel.yaml"] = &fstest.MapFile{Data: []byte(fmt.Sprintf(testChannelTemplate, pkgName, "stable-"+bundleName, bundleName)), Mode: os.ModePerm}
+				}
+				return &filesys
+			}
+
+			reconcileWith := func(key types.NamespacedName, filesys *fstest.MapFS) {
+				mockSource.result = &source.Result{
+					ResolvedSource: &catalog.Spec.Source,
+					State:          source.StateUnpacked,
+					FS:             filesys,
+				}
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			BeforeEach(func() {
+				By("initializing cluster state")
+				otherKey = types.NamespacedName{Name: fmt.Sprintf("catalogd-test-%s", rand.String(8))}
+				catalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type:  "image",
+							Image: &v1alpha1.ImageSource{Ref: "somecatalog:latest"},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, catalog)).To(Succeed())
+				otherCatalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: otherKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type:  "image",
+							Image: &v1alpha1.ImageSource{Ref: "othercatalog:latest"},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, otherCatalog)).To(Succeed())
+
+				mockSource.shouldError = false
+				reconcileWith(cKey, catalogFS("foo", "foo.v1", "foo.v2"))
+				reconcileWith(otherKey, catalogFS("bar", "bar.v1"))
+			})
+
+			AfterEach(func() {
+				By("tearing down cluster state")
+				Expect(cl.Delete(ctx, catalog)).To(Succeed())
+				Expect(cl.Delete(ctx, otherCatalog)).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.Package{})).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.BundleMetadata{})).To(Succeed())
+			})
+
+			It("should keep the objects of both catalogs", func() {
+				packages := &v1alpha1.PackageList{}
+				Expect(cl.List(ctx, packages)).To(Succeed())
+				Expect(packages.Items).To(HaveLen(2))
+
+				bundlemetadatas := &v1alpha1.BundleMetadataList{}
+				Expect(cl.List(ctx, bundlemetadatas)).To(Succeed())
+				Expect(bundlemetadatas.Items).To(HaveLen(3))
+			})
+
+			It("should only prune the objects of the reconciled catalog", func() {
+				recordedEvents()
+				reconcileWith(cKey, catalogFS("foo", "foo.v2"))
+				Expect(recordedEvents()).To(ContainElement("Normal Pruned deleted 0 Packages, 1 BundleMetadata and 0 CatalogMetadata no longer in the catalog contents"))
+
+				bundlemetadatas := &v1alpha1.BundleMetadataList{}
+				Expect(cl.List(ctx, bundlemetadatas, client.MatchingLabels{"catalog": catalog.Name})).To(Succeed())
+				Expect(bundlemetadatas.Items).To(HaveLen(1))
+				Expect(bundlemetadatas.Items[0].Spec.Image).To(Equal("quay.io/test/foo.v2"))
+
+				Expect(cl.List(ctx, bundlemetadatas, client.MatchingLabels{"catalog": otherCatalog.Name})).To(Succeed())
+				Expect(bundlemetadatas.Items).To(HaveLen(1))
+				Expect(bundlemetadatas.Items[0].Spec.Image).To(Equal("quay.io/test/bar.v1"))
+
+				packages := &v1alpha1.PackageList{}
+				Expect(cl.List(ctx, packages, client.MatchingLabels{"catalog": otherCatalog.Name})).To(Succeed())
+				Expect(packages.Items).To(HaveLen(1))
+				Expect(packages.Items[0].Spec.Name).To(Equal("bar"))
+			})
+
+			It("should mark the preferred catalog of packages shipped by both catalogs", func() {
+				preferredCatalogs := func() []string {
+					var found []string
+					for _, key := range []types.NamespacedName{cKey, otherKey} {
+						pkg := &v1alpha1.Package{}
+						Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(key.Name, "foo")}, pkg)).To(Succeed())
+						found = append(found, pkg.Status.PreferredCatalog)
+					}
+					return found
+				}
+
+				By("preferring the catalog whose name sorts first when priorities are equal")
+				reconcileWith(otherKey, catalogFS("foo", "foo.v3"))
+				first := catalog.Name
+				if otherCatalog.Name < first {
+					first = otherCatalog.Name
+				}
+				Expect(preferredCatalogs()).To(Equal([]string{first, first}))
+
+				By("preferring the catalog with the highest priority")
+				Expect(cl.Get(ctx, otherKey, otherCatalog)).To(Succeed())
+				otherCatalog.Spec.Priority = 10
+				Expect(cl.Update(ctx, otherCatalog)).To(Succeed())
+				reconcileWith(otherKey, catalogFS("foo", "foo.v3"))
+				Expect(preferredCatalogs()).To(Equal([]string{otherCatalog.Name, otherCatalog.Name}))
+
+				By("keeping the preferred catalog when the packages are applied again")
+				reconcileWith(cKey, catalogFS("foo", "foo.v1", "foo.v2", "foo.v4"))
+				Expect(preferredCatalogs()).To(Equal([]string{otherCatalog.Name, otherCatalog.Name}))
+			})
+
+			It("should not collide with the objects of a catalog whose joined names are identical", func() {
+				// "catalogd" + "test-<suffix>-foo" joins to the same name as the
+				// catalog's "catalogd-test-<suffix>" + "foo".
+				collidingKey := types.NamespacedName{Name: "catalogd"}
+				colliding := &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: collidingKey.Name},
+					Spec:       catalog.Spec,
+				}
+				Expect(cl.Create(ctx, colliding)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(cl.Delete(ctx, colliding)).To(Succeed())
+				})
+				collidingPkgName := strings.TrimPrefix(cKey.Name, "catalogd-") + "-foo"
+				reconcileWith(collidingKey, catalogFS(collidingPkgName, collidingPkgName+".v1"))
+
+				packages := &v1alpha1.PackageList{}
+				Expect(cl.List(ctx, packages)).To(Succeed())
+				Expect(packages.Items).To(HaveLen(3))
+
+				pkg := &v1alpha1.Package{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "foo")}, pkg)).To(Succeed())
+				Expect(pkg.Spec.Catalog.Name).To(Equal(catalog.Name))
+				Expect(pkg.Spec.Name).To(Equal("foo"))
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(colliding.Name, collidingPkgName)}, pkg)).To(Succeed())
+				Expect(pkg.Spec.Catalog.Name).To(Equal(colliding.Name))
+				Expect(pkg.Spec.Name).To(Equal(collidingPkgName))
 			})
 		})
 
+		When("the catalog contains versioned bundles in several channels", func() {
+			BeforeEach(func() {
+				By("initializing cluster state")
+				catalog = &v1alpha1.Catalog{
+					ObjectMeta: metav1.ObjectMeta{Name: cKey.Name},
+					Spec: v1alpha1.CatalogSpec{
+						Source: v1alpha1.CatalogSource{
+							Type:  "image",
+							Image: &v1alpha1.ImageSource{Ref: "somecatalog:latest"},
+						},
+					},
+				}
+				Expect(cl.Create(ctx, catalog)).To(Succeed())
+
+				filesys := fstest.MapFS{
+					"package.yaml": &fstest.MapFile{Data: []byte(fmt.Sprintf(testPackageTemplate, "stable", "graph")), Mode: os.ModePerm},
+					"channels.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.channel
+package: graph
+name: stable
+entries:
+  - name: graph.v1.0.0
+  - name: graph.v1.1.0
+    replaces: graph.v1.0.0
+  - name: graph.v1.2.0
+    replaces: graph.v1.1.0
+    skips:
+      - graph.v1.1.1
+  - name: graph.v1.1.1
+    replaces: graph.v1.0.0
+---
+schema: olm.channel
+package: graph
+name: candidate
+entries:
+  - name: graph.v1.0.0
+  - name: graph.v1.1.0
+`), Mode: os.ModePerm},
+					"deprecations.yaml": &fstest.MapFile{Data: []byte(`---
+schema: olm.deprecations
+package: graph
+entries:
+  - reference:
+      schema: olm.package
+    message: graph is no longer maintained
+  - reference:
+      schema: olm.channel
+      name: candidate
+    message: the candidate channel is no longer updated
+  - reference:
+      schema: olm.bundle
+      name: graph.v1.0.0
+    message: graph.v1.0.0 has a known vulnerability
+`), Mode: os.ModePerm},
+					"custom.yaml": &fstest.MapFile{Data: []byte(`---
+schema: com.example.maintainer
+package: graph
+name: graph-maintainer
+email: graph@example.com
+---
+schema: com.example.note
+text: first note
+---
+schema: com.example.note
+text: second note
+`), Mode: os.ModePerm},
+				}
+				for _, version := range []string{"1.0.0", "1.1.0", "1.1.1", "1.2.0"} {
+					filesys["graph.v"+version+".yaml"] = &fstest.MapFile{Data: []byte(fmt.Sprintf(testVersionedBundleTemplate, "graph.v"+version, "graph.v"+version, "graph", "graph", version)), Mode: os.ModePerm}
+				}
+				mockSource.shouldError = false
+				mockSource.result = &source.Result{
+					ResolvedSource: &catalog.Spec.Source,
+					State:          source.StateUnpacked,
+					FS:             &filesys,
+				}
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				By("tearing down cluster state")
+				Expect(cl.Delete(ctx, catalog)).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.Package{})).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.BundleMetadata{})).To(Succeed())
+				Expect(cl.DeleteAllOf(ctx, &v1alpha1.CatalogMetadata{})).To(Succeed())
+			})
+
+			It("should publish the head, latest version and bundle count of each channel", func() {
+				pkg := &v1alpha1.Package{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph")}, pkg)).To(Succeed())
+				Expect(pkg.Status.Channels).To(ConsistOf(
+					v1alpha1.ChannelStatus{Name: "stable", Head: "graph.v1.2.0", LatestVersion: "1.2.0", BundleCount: 4},
+					v1alpha1.ChannelStatus{Name: "candidate", Head: "graph.v1.1.0", LatestVersion: "1.1.0", BundleCount: 2},
+				))
+			})
+
+			It("should publish the parsed properties and channels of each bundle", func() {
+				bundle := &v1alpha1.BundleMetadata{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph.v1.1.0")}, bundle)).To(Succeed())
+				Expect(bundle.Status).To(Equal(v1alpha1.BundleMetadataStatus{
+					Version:          "1.1.0",
+					ProvidedGVKs:     []v1alpha1.GroupVersionKind{{Group: "graph.example.com", Version: "v1", Kind: "Graph"}},
+					RequiredPackages: []v1alpha1.RequiredPackage{{PackageName: "dependency", VersionRange: ">=1.0.0"}},
+					Channels:         []string{"candidate", "stable"},
+				}))
+
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph.v1.2.0")}, bundle)).To(Succeed())
+				Expect(bundle.Status.Version).To(Equal("1.2.0"))
+				Expect(bundle.Status.Channels).To(Equal([]string{"stable"}))
+			})
+
+			It("should publish the deprecations of the package, its channels and bundles", func() {
+				pkg := &v1alpha1.Package{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph")}, pkg)).To(Succeed())
+				Expect(pkg.Spec.Deprecation).To(Equal(&v1alpha1.Deprecation{Message: "graph is no longer maintained"}))
+				for _, ch := range pkg.Spec.Channels {
+					if ch.Name == "candidate" {
+						Expect(ch.Deprecation).To(Equal(&v1alpha1.Deprecation{Message: "the candidate channel is no longer updated"}))
+					} else {
+						Expect(ch.Deprecation).To(BeNil())
+					}
+				}
+
+				bundle := &v1alpha1.BundleMetadata{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph.v1.0.0")}, bundle)).To(Succeed())
+				Expect(bundle.Spec.Deprecation).To(Equal(&v1alpha1.Deprecation{Message: "graph.v1.0.0 has a known vulnerability"}))
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph.v1.1.0")}, bundle)).To(Succeed())
+				Expect(bundle.Spec.Deprecation).To(BeNil())
+			})
+
+			It("should create CatalogMetadata resources for the blobs of custom schemas", func() {
+				metadata := &v1alpha1.CatalogMetadataList{}
+				Expect(cl.List(ctx, metadata, client.MatchingLabels{"catalog": catalog.Name})).To(Succeed())
+				Expect(metadata.Items).To(HaveLen(3))
+
+				var maintainer *v1alpha1.CatalogMetadata
+				var notes []string
+				for i, m := range metadata.Items {
+					Expect(m.Spec.Catalog.Name).To(Equal(catalog.Name))
+					switch m.Spec.Schema {
+					case "com.example.maintainer":
+						maintainer = &metadata.Items[i]
+					case "com.example.note":
+						Expect(m.Spec.Package).To(BeEmpty())
+						Expect(m.Spec.Name).To(BeEmpty())
+						notes = append(notes, string(m.Spec.Content))
+					}
+				}
+				Expect(maintainer).ToNot(BeNil())
+				Expect(maintainer.Spec.Package).To(Equal("graph"))
+				Expect(maintainer.Spec.Name).To(Equal("graph-maintainer"))
+				Expect(maintainer.Spec.Content).To(MatchJSON(`{"schema":"com.example.maintainer","package":"graph","name":"graph-maintainer","email":"graph@example.com"}`))
+				Expect(notes).To(ConsistOf(
+					MatchJSON(`{"schema":"com.example.note","text":"first note"}`),
+					MatchJSON(`{"schema":"com.example.note","text":"second note"}`),
+				))
+			})
+
+			It("should fail to create CatalogMetadata for blobs that are too large to store", func() {
+				filesys := mockSource.result.FS.(*fstest.MapFS)
+				(*filesys)["large.yaml"] = &fstest.MapFile{Data: []byte(fmt.Sprintf("schema: com.example.readme\npackage: graph\nname: graph-readme\ntext: %s\n", strings.Repeat("x", 1<<20))), Mode: os.ModePerm}
+
+				_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+				Expect(err).To(MatchError(ContainSubstring(`"com.example.readme" blob for package "graph" named "graph-readme"`)))
+				Expect(err).To(MatchError(ContainSubstring("exceeds the maximum CatalogMetadata size")))
+
+				cat := &v1alpha1.Catalog{}
+				Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+				Expect(cat.Status.Phase).To(Equal(v1alpha1.PhaseFailing))
+			})
+
+			It("should only surface the contents selected by the catalog's filter", func() {
+				filterCatalog := func(filter *v1alpha1.CatalogFilter) {
+					cat := &v1alpha1.Catalog{}
+					Expect(cl.Get(ctx, cKey, cat)).To(Succeed())
+					cat.Spec.Filter = filter
+					Expect(cl.Update(ctx, cat)).To(Succeed())
+					_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: cKey})
+					Expect(err).ToNot(HaveOccurred())
+				}
+				bundleNames := func() []string {
+					bundles := &v1alpha1.BundleMetadataList{}
+					Expect(cl.List(ctx, bundles, client.MatchingLabels{"catalog": catalog.Name})).To(Succeed())
+					var found []string
+					for _, b := range bundles.Items {
+						found = append(found, b.Spec.Name)
+					}
+					return found
+				}
+
+				By("selecting a channel and a version range of a package")
+				filterCatalog(&v1alpha1.CatalogFilter{
+					Include: []v1alpha1.PackageFilter{{Name: "graph", Channels: []string{"stable"}, VersionRange: ">=1.1.0"}},
+				})
+				pkg := &v1alpha1.Package{}
+				Expect(cl.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, "graph")}, pkg)).To(Succeed())
+				Expect(pkg.Spec.Channels).To(HaveLen(1))
+				Expect(pkg.Spec.Channels[0].Name).To(Equal("stable"))
+				Expect(pkg.Spec.Channels[0].Entries).To(HaveLen(3))
+				Expect(bundleNames()).To(ConsistOf("graph.v1.1.0", "graph.v1.1.1", "graph.v1.2.0"))
+
+				By("excluding the package")
+				filterCatalog(&v1alpha1.CatalogFilter{Exclude: []string{"graph"}})
+				packages := &v1alpha1.PackageList{}
+				Expect(cl.List(ctx, packages, client.MatchingLabels{"catalog": catalog.Name})).To(Succeed())
+				Expect(packages.Items).To(BeEmpty())
+				Expect(bundleNames()).To(BeEmpty())
+				metadata := &v1alpha1.CatalogMetadataList{}
+				Expect(cl.List(ctx, metadata, client.MatchingLabels{"catalog": catalog.Name})).To(Succeed())
+				Expect(metadata.Items).To(HaveLen(2))
+				Expect(metadata.Items).To(HaveEach(HaveField("Spec.Schema", "com.example.note")))
+
+				By("removing the filter")
+				filterCatalog(nil)
+				Expect(bundleNames()).To(HaveLen(4))
+			})
+		})
 	})
 })
 
@@ -350,6 +1241,27 @@ properties:
       data: arbitrary-info
 `
 
+const testVersionedBundleTemplate = `---
+image: quay.io/test/%s
+name: %s
+schema: olm.bundle
+package: %s
+properties:
+  - type: olm.package
+    value:
+      packageName: %s
+      version: %s
+  - type: olm.gvk
+    value:
+      group: graph.example.com
+      version: v1
+      kind: Graph
+  - type: olm.package.required
+    value:
+      packageName: dependency
+      versionRange: ">=1.0.0"
+`
+
 const testPackageTemplate = `---
 defaultChannel: %s
 name: %s
@@ -363,3 +1275,11 @@ name: %s
 entries:
   - name: %s
 `
+
+// unpackDurationSamples returns the number of unpack durations observed for
+// the given source type.
+func unpackDurationSamples(sourceType v1alpha1.SourceType) uint64 {
+	m := &dto.Metric{}
+	Expect(metrics.UnpackDuration.WithLabelValues(string(sourceType)).(prometheus.Histogram).Write(m)).To(Succeed())
+	return m.GetHistogram().GetSampleCount()
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// schemaDeprecations is the schema of the blobs that declare the deprecated
+// packages, channels and bundles of a catalog. The version of declcfg in use
+// predates it, so these blobs are loaded into DeclarativeConfig.Others.
+const schemaDeprecations = "olm.deprecations"
+
+// deprecationsBlob is an olm.deprecations blob, which declares the deprecated
+// entities of a single package.
+type deprecationsBlob struct {
+	Package string `json:"package"`
+	Entries []struct {
+		Reference struct {
+			Schema string `json:"schema"`
+			Name   string `json:"name,omitempty"`
+		} `json:"reference"`
+		Message string `json:"message"`
+	} `json:"entries"`
+}
+
+// packageDeprecations holds the deprecations of a package and of its channels
+// and bundles, the latter keyed by their names.
+type packageDeprecations struct {
+	Package  *v1alpha1.Deprecation
+	Channels map[string]*v1alpha1.Deprecation
+	Bundles  map[string]*v1alpha1.Deprecation
+}
+
+// parseDeprecations returns the deprecations declared by the olm.deprecations
+// blobs of the catalog, keyed by package name.
+func parseDeprecations(declCfg *declcfg.DeclarativeConfig) (map[string]*packageDeprecations, error) {
+	deprecations := map[string]*packageDeprecations{}
+	for _, meta := range declCfg.Others {
+		if meta.Schema != schemaDeprecations {
+			continue
+		}
+		var blob deprecationsBlob
+		if err := json.Unmarshal(meta.Blob, &blob); err != nil {
+			return nil, fmt.Errorf("parse %q blob of package %q: %v", schemaDeprecations, meta.Package, err)
+		}
+		pkgDeprecations, ok := deprecations[blob.Package]
+		if !ok {
+			pkgDeprecations = &packageDeprecations{
+				Channels: map[string]*v1alpha1.Deprecation{},
+				Bundles:  map[string]*v1alpha1.Deprecation{},
+			}
+			deprecations[blob.Package] = pkgDeprecations
+		}
+		for _, entry := range blob.Entries {
+			deprecation := &v1alpha1.Deprecation{Message: entry.Message}
+			switch entry.Reference.Schema {
+			case declcfg.SchemaPackage:
+				pkgDeprecations.Package = deprecation
+			case declcfg.SchemaChannel:
+				pkgDeprecations.Channels[entry.Reference.Name] = deprecation
+			case declcfg.SchemaBundle:
+				pkgDeprecations.Bundles[entry.Reference.Name] = deprecation
+			default:
+				return nil, fmt.Errorf("%q blob of package %q references unsupported schema %q", schemaDeprecations, blob.Package, entry.Reference.Schema)
+			}
+		}
+	}
+	return deprecations, nil
+}
+
+// packageDeprecation returns the deprecation of the package, if any.
+func (d *packageDeprecations) packageDeprecation() *v1alpha1.Deprecation {
+	if d == nil {
+		return nil
+	}
+	return d.Package
+}
+
+// channelDeprecation returns the deprecation of the package's channel with
+// the given name, if any.
+func (d *packageDeprecations) channelDeprecation(name string) *v1alpha1.Deprecation {
+	if d == nil {
+		return nil
+	}
+	return d.Channels[name]
+}
+
+// bundleDeprecation returns the deprecation of the package's bundle with the
+// given name, if any.
+func (d *packageDeprecations) bundleDeprecation(name string) *v1alpha1.Deprecation {
+	if d == nil {
+		return nil
+	}
+	return d.Bundles[name]
+}
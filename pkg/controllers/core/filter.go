@@ -0,0 +1,136 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// packageSelector selects the channels and bundles of a single package.
+type packageSelector struct {
+	channels     sets.Set[string]
+	versionRange semver.Range
+}
+
+// selectsChannel reports whether the selector selects the channel with the
+// given name.
+func (s *packageSelector) selectsChannel(name string) bool {
+	return s.channels.Len() == 0 || s.channels.Has(name)
+}
+
+// selectsVersion reports whether the selector selects bundles of the given
+// version, where ok reports whether the bundle's version is known.
+func (s *packageSelector) selectsVersion(version semver.Version, ok bool) bool {
+	return s.versionRange == nil || (ok && s.versionRange(version))
+}
+
+// selectsAllBundles reports whether the selector selects every bundle of the
+// package, rather than only those of its selected channel entries.
+func (s *packageSelector) selectsAllBundles() bool {
+	return s.channels.Len() == 0 && s.versionRange == nil
+}
+
+// filterContents returns the contents of the catalog that are selected by the
+// given filter. Channels left without entries are dropped, as are bundles
+// that are not an entry of any selected channel, unless all of a package's
+// bundles are selected. Blobs of other schemas are kept when they belong to a
+// selected package or to no package at all.
+func filterContents(declCfg *declcfg.DeclarativeConfig, filter *v1alpha1.CatalogFilter) (*declcfg.DeclarativeConfig, error) {
+	if filter == nil {
+		return declCfg, nil
+	}
+
+	selectors := map[string]*packageSelector{}
+	for _, pkgFilter := range filter.Include {
+		selector := &packageSelector{channels: sets.New(pkgFilter.Channels...)}
+		if pkgFilter.VersionRange != "" {
+			versionRange, err := semver.ParseRange(pkgFilter.VersionRange)
+			if err != nil {
+				return nil, fmt.Errorf("parse version range of package %q: %v", pkgFilter.Name, err)
+			}
+			selector.versionRange = versionRange
+		}
+		selectors[pkgFilter.Name] = selector
+	}
+	excluded := sets.New(filter.Exclude...)
+	selectorFor := func(pkgName string) *packageSelector {
+		if excluded.Has(pkgName) {
+			return nil
+		}
+		if len(filter.Include) == 0 {
+			return &packageSelector{}
+		}
+		return selectors[pkgName]
+	}
+
+	filtered := &declcfg.DeclarativeConfig{}
+	for _, pkg := range declCfg.Packages {
+		if selectorFor(pkg.Name) != nil {
+			filtered.Packages = append(filtered.Packages, pkg)
+		}
+	}
+
+	versions := bundleVersions(declCfg.Bundles)
+	selectedBundles := map[string]sets.Set[string]{}
+	for _, ch := range declCfg.Channels {
+		selector := selectorFor(ch.Package)
+		if selector == nil || !selector.selectsChannel(ch.Name) {
+			continue
+		}
+		var entries []declcfg.ChannelEntry
+		for _, entry := range ch.Entries {
+			version, ok := versions[ch.Package][entry.Name]
+			if selector.selectsVersion(version, ok) {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		ch.Entries = entries
+		filtered.Channels = append(filtered.Channels, ch)
+		if selectedBundles[ch.Package] == nil {
+			selectedBundles[ch.Package] = sets.New[string]()
+		}
+		for _, entry := range entries {
+			selectedBundles[ch.Package].Insert(entry.Name)
+		}
+	}
+
+	for _, bundle := range declCfg.Bundles {
+		selector := selectorFor(bundle.Package)
+		if selector == nil {
+			continue
+		}
+		if selector.selectsAllBundles() || selectedBundles[bundle.Package].Has(bundle.Name) {
+			filtered.Bundles = append(filtered.Bundles, bundle)
+		}
+	}
+
+	for _, meta := range declCfg.Others {
+		if meta.Package == "" || selectorFor(meta.Package) != nil {
+			filtered.Others = append(filtered.Others, meta)
+		}
+	}
+	return filtered, nil
+}
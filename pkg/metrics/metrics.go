@@ -0,0 +1,126 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// FailureReasonUnpack is the failure reason recorded when a Catalog's
+	// source could not be unpacked.
+	FailureReasonUnpack = "unpack"
+	// FailureReasonVerify is the failure reason recorded when a Catalog's
+	// image did not satisfy the verification policy of its source.
+	FailureReasonVerify = "verify"
+	// FailureReasonTimeout is the failure reason recorded when a Catalog's
+	// source was not unpacked within its unpack timeout.
+	FailureReasonTimeout = "timeout"
+	// FailureReasonLoad is the failure reason recorded when the unpacked
+	// contents of a Catalog could not be loaded as a file-based catalog.
+	FailureReasonLoad = "load"
+	// FailureReasonFilter is the failure reason recorded when the contents of
+	// a Catalog could not be filtered.
+	FailureReasonFilter = "filter"
+	// FailureReasonStore is the failure reason recorded when the contents of a
+	// Catalog could not be stored to be served.
+	FailureReasonStore = "store"
+	// FailureReasonSyncPackages is the failure reason recorded when the
+	// Packages of a Catalog could not be synced.
+	FailureReasonSyncPackages = "sync_packages"
+	// FailureReasonSyncBundleMetadata is the failure reason recorded when the
+	// BundleMetadata of a Catalog could not be synced.
+	FailureReasonSyncBundleMetadata = "sync_bundle_metadata"
+	// FailureReasonSyncCatalogMetadata is the failure reason recorded when the
+	// CatalogMetadata of a Catalog could not be synced.
+	FailureReasonSyncCatalogMetadata = "sync_catalog_metadata"
+
+	// ResourcePackages is the resource label value of Package syncs.
+	ResourcePackages = "packages"
+	// ResourceBundleMetadata is the resource label value of BundleMetadata syncs.
+	ResourceBundleMetadata = "bundlemetadata"
+	// ResourceCatalogMetadata is the resource label value of CatalogMetadata syncs.
+	ResourceCatalogMetadata = "catalogmetadata"
+)
+
+var (
+	// UnpackDuration observes the time taken to unpack new contents from the
+	// source of a Catalog, from the start of the attempt until its contents are
+	// unpacked. Polls that find the contents unchanged are not observed.
+	UnpackDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "catalogd",
+		Name:      "unpack_duration_seconds",
+		Help:      "Time taken from the start of an attempt to unpack the source of a Catalog until its contents are unpacked, by source type.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	}, []string{"source_type"})
+
+	// UnpackFailures counts the failed attempts to unpack and sync Catalogs.
+	UnpackFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "catalogd",
+		Name:      "unpack_failures_total",
+		Help:      "Number of failed attempts to unpack and sync a Catalog, by source type and reason.",
+	}, []string{"source_type", "reason"})
+
+	// SyncDuration observes the time taken to sync the Packages,
+	// BundleMetadata and CatalogMetadata of Catalogs.
+	SyncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "catalogd",
+		Name:      "sync_duration_seconds",
+		Help:      "Time taken to sync the objects of a Catalog, by resource.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+	}, []string{"resource"})
+
+	// Packages is the number of Packages of each Catalog.
+	Packages = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "catalogd",
+		Name:      "catalog_packages",
+		Help:      "Number of Packages of a Catalog.",
+	}, []string{"catalog"})
+
+	// BundleMetadata is the number of BundleMetadata of each Catalog.
+	BundleMetadata = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "catalogd",
+		Name:      "catalog_bundle_metadata",
+		Help:      "Number of BundleMetadata of a Catalog.",
+	}, []string{"catalog"})
+
+	// ContentSize is the size of the unpacked contents of each Catalog.
+	ContentSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "catalogd",
+		Name:      "catalog_content_size_bytes",
+		Help:      "Total size of the files in the unpacked contents of a Catalog.",
+	}, []string{"catalog"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		UnpackDuration,
+		UnpackFailures,
+		SyncDuration,
+		Packages,
+		BundleMetadata,
+		ContentSize,
+	)
+}
+
+// DeleteCatalog removes the series of the named Catalog.
+func DeleteCatalog(catalog string) {
+	Packages.DeleteLabelValues(catalog)
+	BundleMetadata.DeleteLabelValues(catalog)
+	ContentSize.DeleteLabelValues(catalog)
+}
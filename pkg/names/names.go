@@ -0,0 +1,80 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package names derives the names of the objects that catalogd creates for
+// the contents of a Catalog.
+package names
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// hashLength is the number of hex characters of the hash that is appended to
+// each name, which keeps names unique even after they are sanitized or
+// truncated.
+const hashLength = 16
+
+// ForCatalogObject returns the name of the object created for the catalog
+// contents object (e.g. a package or bundle) with the given name in the
+// Catalog with the given name.
+//
+// The returned name is a valid DNS subdomain of at most 253 characters. It
+// consists of a readable prefix derived from both names, followed by a hash
+// of the pair of names so that different pairs never share a name, even when
+// their readable prefixes are identical (e.g. catalog "a-b" with package "c"
+// and catalog "a" with package "b-c").
+func ForCatalogObject(catalogName, objectName string) string {
+	// Catalog names are DNS subdomains and can't contain a "/", so the hashed
+	// input is unambiguous.
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(catalogName+"/"+objectName)))[:hashLength]
+
+	prefix := sanitize(catalogName + "-" + objectName)
+	if maxLen := validation.DNS1123SubdomainMaxLength - len(hash) - 1; len(prefix) > maxLen {
+		prefix = sanitize(prefix[:maxLen])
+	}
+	if prefix == "" {
+		return hash
+	}
+	return prefix + "-" + hash
+}
+
+// sanitize converts s into a valid DNS subdomain by lowercasing it, replacing
+// invalid characters with "-" and trimming each of its dot-separated labels
+// so that they start and end with an alphanumeric character.
+func sanitize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, s)
+
+	var labels []string
+	for _, label := range strings.Split(s, ".") {
+		if label = strings.Trim(label, "-"); label != "" {
+			labels = append(labels, label)
+		}
+	}
+	return strings.Join(labels, ".")
+}
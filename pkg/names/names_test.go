@@ -0,0 +1,74 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package names_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/operator-framework/catalogd/pkg/names"
+)
+
+var _ = Describe("ForCatalogObject", func() {
+	expectValid := func(name string) {
+		Expect(validation.IsDNS1123Subdomain(name)).To(BeEmpty())
+	}
+
+	It("is deterministic", func() {
+		Expect(names.ForCatalogObject("operatorhubio", "prometheus")).To(Equal(names.ForCatalogObject("operatorhubio", "prometheus")))
+	})
+
+	It("keeps the catalog and object names readable", func() {
+		name := names.ForCatalogObject("operatorhubio", "prometheus.v0.47.0")
+		expectValid(name)
+		Expect(name).To(HavePrefix("operatorhubio-prometheus.v0.47.0-"))
+	})
+
+	It("does not collide when the joined names are identical", func() {
+		Expect(names.ForCatalogObject("a-b", "c")).ToNot(Equal(names.ForCatalogObject("a", "b-c")))
+	})
+
+	It("does not collide when the sanitized names are identical", func() {
+		Expect(names.ForCatalogObject("catalog", "Foo")).ToNot(Equal(names.ForCatalogObject("catalog", "foo")))
+	})
+
+	It("sanitizes invalid characters", func() {
+		name := names.ForCatalogObject("catalog", "My_Operator..v1.0.0+build.1")
+		expectValid(name)
+		Expect(name).To(HavePrefix("catalog-my-operator.v1.0.0-build.1-"))
+	})
+
+	It("sanitizes names without any valid characters", func() {
+		expectValid(names.ForCatalogObject("catalog", ".-_"))
+		expectValid(names.ForCatalogObject("", "_"))
+	})
+
+	It("truncates long names", func() {
+		long := names.ForCatalogObject(strings.Repeat("c", 253), strings.Repeat("p", 253))
+		expectValid(long)
+		Expect(long).To(HaveLen(validation.DNS1123SubdomainMaxLength))
+		Expect(long).ToNot(Equal(names.ForCatalogObject(strings.Repeat("c", 253), strings.Repeat("p", 252))))
+	})
+
+	It("truncates long names without leaving an invalid label", func() {
+		expectValid(names.ForCatalogObject("catalog", strings.Repeat("a", 227)+"."+strings.Repeat("b", 20)))
+		expectValid(names.ForCatalogObject("catalog", strings.Repeat("a", 226)+"-"+strings.Repeat("b", 20)))
+	})
+})
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// CatalogsPath is the URL path below which the contents of all catalogs are
+// served.
+const CatalogsPath = "/catalogs/"
+
+// contentFile is the name of the file, within a catalog's directory, that
+// holds the catalog's file-based catalog contents as a stream of JSON blobs.
+const contentFile = "all.json"
+
+// LocalDir is a storage Instance that stores the contents of each catalog in
+// a directory of the same name below RootDir, and serves them below
+// CatalogsPath as /catalogs/<catalog>/all.json.
+type LocalDir struct {
+	// RootDir is the directory in which the contents of catalogs are stored.
+	RootDir string
+
+	// BaseURL is the externally reachable URL of the server that serves the
+	// Handler, used to build the content URLs of catalogs.
+	BaseURL *url.URL
+}
+
+var _ Instance = &LocalDir{}
+
+// Store writes the contents of the catalog to a temporary file that is
+// renamed into place once complete, so that requests for the catalog are
+// only ever served complete contents.
+func (s *LocalDir) Store(catalog string, fbc *declcfg.DeclarativeConfig) error {
+	catalogDir := filepath.Join(s.RootDir, catalog)
+	if err := os.MkdirAll(catalogDir, 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(catalogDir, ".all-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := declcfg.WriteJSON(*fbc, f); err != nil {
+		f.Close()
+		return fmt.Errorf("write catalog contents: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filepath.Join(catalogDir, contentFile))
+}
+
+func (s *LocalDir) Delete(catalog string) error {
+	return os.RemoveAll(filepath.Join(s.RootDir, catalog))
+}
+
+func (s *LocalDir) ContentExists(catalog string) bool {
+	_, err := os.Stat(filepath.Join(s.RootDir, catalog, contentFile))
+	return err == nil
+}
+
+func (s *LocalDir) ContentURL(catalog string) string {
+	return s.BaseURL.JoinPath(CatalogsPath, catalog, contentFile).String()
+}
+
+// ServeHTTP serves the stored contents of catalogs. Only the content file of
+// each catalog is served; directory listings are not.
+func (s *LocalDir) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.StripPrefix(CatalogsPath, http.FileServer(http.FS(contentFS{os.DirFS(s.RootDir)}))).ServeHTTP(w, r)
+}
+
+// contentFS is a filesystem that only allows the content files of catalogs,
+// at <catalog>/all.json, to be opened.
+type contentFS struct {
+	fs.FS
+}
+
+func (c contentFS) Open(name string) (fs.File, error) {
+	if catalog, file := path.Split(name); file != contentFile || catalog == "" || strings.Contains(strings.TrimSuffix(catalog, "/"), "/") {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return c.FS.Open(name)
+}
@@ -0,0 +1,92 @@
+package storage_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/operator-framework/catalogd/pkg/storage"
+)
+
+var _ = Describe("LocalDir Storage", func() {
+	var (
+		store  *storage.LocalDir
+		server *httptest.Server
+		fbc    *declcfg.DeclarativeConfig
+	)
+
+	BeforeEach(func() {
+		mux := http.NewServeMux()
+		store = &storage.LocalDir{RootDir: GinkgoT().TempDir()}
+		mux.Handle(storage.CatalogsPath, store)
+		server = httptest.NewServer(mux)
+		DeferCleanup(server.Close)
+
+		var err error
+		store.BaseURL, err = url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+
+		fbc = &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "foo", DefaultChannel: "stable"}},
+			Channels: []declcfg.Channel{{Schema: declcfg.SchemaChannel, Package: "foo", Name: "stable", Entries: []declcfg.ChannelEntry{{Name: "foo.v1"}}}},
+		}
+	})
+
+	get := func(u string) (int, string) {
+		resp, err := http.Get(u)
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		return resp.StatusCode, string(body)
+	}
+
+	It("serves the stored contents of a catalog at its content URL", func() {
+		Expect(store.ContentExists("test-catalog")).To(BeFalse())
+		Expect(store.Store("test-catalog", fbc)).To(Succeed())
+		Expect(store.ContentExists("test-catalog")).To(BeTrue())
+		Expect(store.ContentURL("test-catalog")).To(Equal(server.URL + "/catalogs/test-catalog/all.json"))
+
+		code, body := get(store.ContentURL("test-catalog"))
+		Expect(code).To(Equal(http.StatusOK))
+		served, err := declcfg.LoadReader(strings.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(served.Packages).To(HaveLen(1))
+		Expect(served.Packages[0].Name).To(Equal("foo"))
+		Expect(served.Channels).To(HaveLen(1))
+	})
+
+	It("replaces previously stored contents", func() {
+		Expect(store.Store("test-catalog", fbc)).To(Succeed())
+		fbc.Packages[0].Name = "bar"
+		Expect(store.Store("test-catalog", fbc)).To(Succeed())
+
+		_, body := get(store.ContentURL("test-catalog"))
+		Expect(body).To(ContainSubstring(`"name": "bar"`))
+		Expect(body).ToNot(ContainSubstring(`"name": "foo"`))
+	})
+
+	It("stops serving the contents of a deleted catalog", func() {
+		Expect(store.Store("test-catalog", fbc)).To(Succeed())
+		Expect(store.Delete("test-catalog")).To(Succeed())
+		Expect(store.ContentExists("test-catalog")).To(BeFalse())
+
+		code, _ := get(store.ContentURL("test-catalog"))
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+
+	It("does not serve directory listings", func() {
+		Expect(store.Store("test-catalog", fbc)).To(Succeed())
+
+		code, _ := get(server.URL + "/catalogs/")
+		Expect(code).To(Equal(http.StatusNotFound))
+		code, _ = get(server.URL + "/catalogs/test-catalog/")
+		Expect(code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"net/http"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// Instance is a storage instance that stores the file-based catalog contents
+// of Catalogs and serves them over HTTP.
+type Instance interface {
+	// Store persists the contents of the named catalog, replacing any
+	// contents previously stored for it.
+	Store(catalog string, fbc *declcfg.DeclarativeConfig) error
+
+	// Delete removes the stored contents of the named catalog.
+	Delete(catalog string) error
+
+	// ContentExists reports whether contents are stored for the named catalog.
+	ContentExists(catalog string) bool
+
+	// ContentURL returns the URL at which the stored contents of the named
+	// catalog are served.
+	ContentURL(catalog string) string
+
+	http.Handler
+}
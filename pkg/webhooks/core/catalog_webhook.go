@@ -0,0 +1,251 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/operator-framework/catalogd/api/core/v1alpha1"
+)
+
+// CatalogValidator validates Catalog objects as they are created and updated,
+// so that invalid sources are rejected up front rather than surfacing later
+// as unpack failures.
+type CatalogValidator struct {
+	// Reader is used to look up the secrets referenced by a Catalog's source.
+	Reader client.Reader
+
+	// SystemNamespace is the namespace that catalogd is deployed in and from
+	// which referenced secrets are read.
+	SystemNamespace string
+}
+
+var _ webhook.CustomValidator = &CatalogValidator{}
+
+// minPollInterval is the shortest interval at which image sources may be
+// polled, so that Catalogs do not hammer their registries.
+const minPollInterval = time.Minute
+
+//+kubebuilder:webhook:path=/validate-catalogd-operatorframework-io-v1alpha1-catalog,mutating=false,failurePolicy=fail,sideEffects=None,groups=catalogd.operatorframework.io,resources=catalogs,verbs=create;update,versions=v1alpha1,name=vcatalog.catalogd.operatorframework.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the Catalog validating webhook with the Manager.
+func (v *CatalogValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Catalog{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate validates the source of a new Catalog.
+func (v *CatalogValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	catalog, ok := obj.(*v1alpha1.Catalog)
+	if !ok {
+		return fmt.Errorf("expected a Catalog but got %T", obj)
+	}
+	errs := v.validateSource(ctx, &catalog.Spec.Source, nil, field.NewPath("spec", "source"))
+	errs = append(errs, validateFilter(catalog.Spec.Filter, field.NewPath("spec", "filter"))...)
+	return v.invalid(catalog, errs)
+}
+
+// ValidateUpdate validates the source of an updated Catalog and ensures that
+// none of its immutable fields have changed. Catalogs that are being deleted
+// are not validated, so that removing their finalizers is never blocked, and
+// only the parts of the spec that changed are validated, so that an update is
+// not rejected because a secret referenced by an unchanged field was deleted.
+func (v *CatalogValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	oldCatalog, ok := oldObj.(*v1alpha1.Catalog)
+	if !ok {
+		return fmt.Errorf("expected a Catalog but got %T", oldObj)
+	}
+	catalog, ok := newObj.(*v1alpha1.Catalog)
+	if !ok {
+		return fmt.Errorf("expected a Catalog but got %T", newObj)
+	}
+	if catalog.DeletionTimestamp != nil {
+		return nil
+	}
+
+	sourcePath := field.NewPath("spec", "source")
+	var errs field.ErrorList
+	if !equality.Semantic.DeepEqual(catalog.Spec.Source, oldCatalog.Spec.Source) {
+		if catalog.Spec.Source.Type != oldCatalog.Spec.Source.Type {
+			errs = append(errs, field.Invalid(sourcePath.Child("type"), string(catalog.Spec.Source.Type), "field is immutable"))
+		}
+		errs = append(errs, v.validateSource(ctx, &catalog.Spec.Source, &oldCatalog.Spec.Source, sourcePath)...)
+	}
+	if !equality.Semantic.DeepEqual(catalog.Spec.Filter, oldCatalog.Spec.Filter) {
+		errs = append(errs, validateFilter(catalog.Spec.Filter, field.NewPath("spec", "filter"))...)
+	}
+	return v.invalid(catalog, errs)
+}
+
+// ValidateDelete allows all Catalogs to be deleted.
+func (v *CatalogValidator) ValidateDelete(_ context.Context, _ runtime.Object) error {
+	return nil
+}
+
+// validateSource ensures that the configuration of the source's type, and
+// only that configuration, is set and that it is valid. The secrets that the
+// source references are only looked up when they differ from those of old,
+// which is nil for new Catalogs.
+func (v *CatalogValidator) validateSource(ctx context.Context, src, old *v1alpha1.CatalogSource, fldPath *field.Path) field.ErrorList {
+	sources := []struct {
+		sourceType v1alpha1.SourceType
+		isSet      bool
+	}{
+		{v1alpha1.SourceTypeImage, src.Image != nil},
+		{v1alpha1.SourceTypeGit, src.Git != nil},
+		{v1alpha1.SourceTypeHTTP, src.HTTP != nil},
+		{v1alpha1.SourceTypeConfigMaps, len(src.ConfigMaps) > 0},
+	}
+
+	var (
+		errs      field.ErrorList
+		supported []string
+		known     bool
+	)
+	for _, s := range sources {
+		supported = append(supported, string(s.sourceType))
+		if s.sourceType == src.Type {
+			known = true
+			if !s.isSet {
+				errs = append(errs, field.Required(fldPath.Child(string(s.sourceType)), fmt.Sprintf("must be set for source type %q", src.Type)))
+			}
+		} else if s.isSet {
+			errs = append(errs, field.Forbidden(fldPath.Child(string(s.sourceType)), fmt.Sprintf("must not be set for source type %q", src.Type)))
+		}
+	}
+	if !known {
+		return field.ErrorList{field.NotSupported(fldPath.Child("type"), string(src.Type), supported)}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if src.Type == v1alpha1.SourceTypeImage {
+		var oldImage *v1alpha1.ImageSource
+		if old != nil {
+			oldImage = old.Image
+		}
+		errs = append(errs, v.validateImageSource(ctx, src.Image, oldImage, fldPath.Child("image"))...)
+	}
+	return errs
+}
+
+func (v *CatalogValidator) validateImageSource(ctx context.Context, src, old *v1alpha1.ImageSource, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if _, err := name.ParseReference(src.Ref); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("ref"), src.Ref, err.Error()))
+	} else if _, err := name.NewDigest(src.Ref); err != nil && src.DigestPolicy == v1alpha1.DigestPolicyRequire {
+		errs = append(errs, field.Invalid(fldPath.Child("ref"), src.Ref, fmt.Sprintf("must be a digest reference when the digest policy is %q", src.DigestPolicy)))
+	}
+	var oldPullSecret string
+	var oldPollInterval *metav1.Duration
+	var oldVerification *v1alpha1.ImageVerification
+	if old != nil {
+		oldPullSecret, oldPollInterval, oldVerification = old.PullSecret, old.PollInterval, old.Verification
+	}
+	// Intervals that are unchanged from old are accepted, so that Catalogs
+	// are not rejected for intervals that they were already polled at.
+	if src.PollInterval != nil && src.PollInterval.Duration < minPollInterval && !equality.Semantic.DeepEqual(src.PollInterval, oldPollInterval) {
+		errs = append(errs, field.Invalid(fldPath.Child("pollInterval"), src.PollInterval.Duration.String(), fmt.Sprintf("must be at least %s", minPollInterval)))
+	}
+	if src.PullSecret != "" {
+		errs = append(errs, v.validateSecret(ctx, src.PullSecret, oldPullSecret, fldPath.Child("pullSecret"))...)
+	}
+	if src.Verification != nil {
+		errs = append(errs, v.validateVerification(ctx, src.Verification, oldVerification, fldPath.Child("verification"))...)
+	}
+	return errs
+}
+
+// validateVerification ensures that the secret of public keys referenced by
+// the verification policy exists. It is only looked up when it differs from
+// that of the old policy, which is nil when the policy is new.
+func (v *CatalogValidator) validateVerification(ctx context.Context, policy, old *v1alpha1.ImageVerification, fldPath *field.Path) field.ErrorList {
+	var oldKeys string
+	if old != nil {
+		oldKeys = old.PublicKeys.Name
+	}
+	return v.validateSecret(ctx, policy.PublicKeys.Name, oldKeys, fldPath.Child("publicKeys", "name"))
+}
+
+// validateSecret ensures that the named secret exists in the system namespace.
+// The secret is not looked up when its name is unchanged from old, so that
+// Catalogs are not rejected for secrets that they already referenced.
+func (v *CatalogValidator) validateSecret(ctx context.Context, name, old string, fldPath *field.Path) field.ErrorList {
+	if name == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	if name == old {
+		return nil
+	}
+	secret := &corev1.Secret{}
+	err := v.Reader.Get(ctx, client.ObjectKey{Namespace: v.SystemNamespace, Name: name}, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		return field.ErrorList{field.NotFound(fldPath, name)}
+	case err != nil:
+		return field.ErrorList{field.InternalError(fldPath, fmt.Errorf("get secret %s/%s: %v", v.SystemNamespace, name, err))}
+	}
+	return nil
+}
+
+// validateFilter ensures that each package is only included once and that the
+// version ranges of included packages are valid semver ranges.
+func validateFilter(filter *v1alpha1.CatalogFilter, fldPath *field.Path) field.ErrorList {
+	if filter == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	included := sets.New[string]()
+	for i, pkgFilter := range filter.Include {
+		pkgPath := fldPath.Child("include").Index(i)
+		if included.Has(pkgFilter.Name) {
+			errs = append(errs, field.Duplicate(pkgPath.Child("name"), pkgFilter.Name))
+		}
+		included.Insert(pkgFilter.Name)
+		if pkgFilter.VersionRange != "" {
+			if _, err := semver.ParseRange(pkgFilter.VersionRange); err != nil {
+				errs = append(errs, field.Invalid(pkgPath.Child("versionRange"), pkgFilter.VersionRange, err.Error()))
+			}
+		}
+	}
+	return errs
+}
+
+func (v *CatalogValidator) invalid(catalog *v1alpha1.Catalog, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(v1alpha1.GroupVersion.WithKind("Catalog").GroupKind(), catalog.Name, errs)
+}
@@ -0,0 +1,192 @@
+package core_test
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/catalogd/api/core/v1alpha1"
+	"github.com/operator-framework/catalogd/pkg/webhooks/core"
+)
+
+var _ = Describe("CatalogValidator", func() {
+	const systemNamespace = "catalogd-system"
+
+	var (
+		ctx       context.Context
+		validator *core.CatalogValidator
+		catalog   *v1alpha1.Catalog
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sch := runtime.NewScheme()
+		Expect(corev1.AddToScheme(sch)).To(Succeed())
+		validator = &core.CatalogValidator{
+			Reader: fake.NewClientBuilder().WithScheme(sch).WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: systemNamespace},
+			}).Build(),
+			SystemNamespace: systemNamespace,
+		}
+		catalog = &v1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-catalog"},
+			Spec: v1alpha1.CatalogSpec{
+				Source: v1alpha1.CatalogSource{
+					Type:  v1alpha1.SourceTypeImage,
+					Image: &v1alpha1.ImageSource{Ref: "quay.io/test/catalog:latest", PullSecret: "pull-secret"},
+				},
+			},
+		}
+	})
+
+	It("accepts a valid catalog", func() {
+		Expect(validator.ValidateCreate(ctx, catalog)).To(Succeed())
+	})
+
+	It("rejects an unknown source type", func() {
+		catalog.Spec.Source.Type = "invalid-source"
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.type")))
+	})
+
+	It("rejects a source type without its configuration", func() {
+		catalog.Spec.Source.Type = v1alpha1.SourceTypeGit
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.git: Required value")))
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image: Forbidden")))
+	})
+
+	It("rejects an unparsable image reference", func() {
+		catalog.Spec.Source.Image.Ref = "quay.io/test/Catalog:latest"
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.ref")))
+	})
+
+	It("rejects a tag reference when the digest policy requires a digest", func() {
+		catalog.Spec.Source.Image.DigestPolicy = v1alpha1.DigestPolicyRequire
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.ref")))
+
+		catalog.Spec.Source.Image.Ref = "quay.io/test/catalog@sha256:" + strings.Repeat("a", 64)
+		Expect(validator.ValidateCreate(ctx, catalog)).To(Succeed())
+	})
+
+	It("rejects a poll interval shorter than a minute", func() {
+		catalog.Spec.Source.Image.PollInterval = &metav1.Duration{Duration: 10 * time.Second}
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring(`spec.source.image.pollInterval: Invalid value: "10s": must be at least 1m0s`)))
+
+		catalog.Spec.Source.Image.PollInterval = &metav1.Duration{Duration: time.Minute}
+		Expect(validator.ValidateCreate(ctx, catalog)).To(Succeed())
+	})
+
+	It("accepts an update that keeps a poll interval shorter than a minute", func() {
+		catalog.Spec.Source.Image.PollInterval = &metav1.Duration{Duration: 10 * time.Second}
+		updated := catalog.DeepCopy()
+		updated.Spec.Source.Image.Ref = "quay.io/test/catalog:v2"
+		Expect(validator.ValidateUpdate(ctx, catalog, updated)).To(Succeed())
+
+		updated.Spec.Source.Image.PollInterval = &metav1.Duration{Duration: 5 * time.Second}
+		err := validator.ValidateUpdate(ctx, catalog, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.pollInterval")))
+	})
+
+	It("rejects a pull secret that does not exist", func() {
+		catalog.Spec.Source.Image.PullSecret = "missing"
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.pullSecret: Not found")))
+	})
+
+	It("accepts a verification policy whose secrets exist", func() {
+		catalog.Spec.Source.Image.Verification = &v1alpha1.ImageVerification{
+			PublicKeys: corev1.LocalObjectReference{Name: "pull-secret"},
+		}
+		Expect(validator.ValidateCreate(ctx, catalog)).To(Succeed())
+	})
+
+	It("rejects an invalid verification policy", func() {
+		catalog.Spec.Source.Image.Verification = &v1alpha1.ImageVerification{}
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.verification.publicKeys.name: Required value")))
+
+		catalog.Spec.Source.Image.Verification = &v1alpha1.ImageVerification{
+			PublicKeys: corev1.LocalObjectReference{Name: "missing"},
+		}
+		err = validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.verification.publicKeys.name: Not found")))
+	})
+
+	It("accepts a valid filter", func() {
+		catalog.Spec.Filter = &v1alpha1.CatalogFilter{
+			Include: []v1alpha1.PackageFilter{{Name: "foo", Channels: []string{"stable"}, VersionRange: ">=1.0.0 <2.0.0"}},
+			Exclude: []string{"bar"},
+		}
+		Expect(validator.ValidateCreate(ctx, catalog)).To(Succeed())
+	})
+
+	It("rejects an invalid filter", func() {
+		catalog.Spec.Filter = &v1alpha1.CatalogFilter{
+			Include: []v1alpha1.PackageFilter{{Name: "foo", VersionRange: "not-a-range"}, {Name: "foo"}},
+		}
+		err := validator.ValidateCreate(ctx, catalog)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.filter.include[0].versionRange")))
+		Expect(err).To(MatchError(ContainSubstring("spec.filter.include[1].name: Duplicate value")))
+	})
+
+	It("accepts an update of the image reference", func() {
+		updated := catalog.DeepCopy()
+		updated.Spec.Source.Image.Ref = "quay.io/test/catalog:v2"
+		Expect(validator.ValidateUpdate(ctx, catalog, updated)).To(Succeed())
+	})
+
+	It("only looks up secrets referenced by changed fields on update", func() {
+		catalog.Spec.Source.Image.PullSecret = "deleted-secret"
+		updated := catalog.DeepCopy()
+		updated.Labels = map[string]string{"foo": "bar"}
+		Expect(validator.ValidateUpdate(ctx, catalog, updated)).To(Succeed())
+
+		updated.Spec.Source.Image.Ref = "quay.io/test/catalog:v2"
+		Expect(validator.ValidateUpdate(ctx, catalog, updated)).To(Succeed())
+
+		updated.Spec.Source.Image.PullSecret = "missing"
+		err := validator.ValidateUpdate(ctx, catalog, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.image.pullSecret: Not found")))
+	})
+
+	It("accepts any update of a catalog that is being deleted", func() {
+		updated := catalog.DeepCopy()
+		updated.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		updated.Spec.Source.Image.PullSecret = "missing"
+		Expect(validator.ValidateUpdate(ctx, catalog, updated)).To(Succeed())
+	})
+
+	It("rejects an update of the source type", func() {
+		updated := catalog.DeepCopy()
+		updated.Spec.Source = v1alpha1.CatalogSource{
+			Type: v1alpha1.SourceTypeHTTP,
+			HTTP: &v1alpha1.HTTPSource{URL: "https://example.com/catalog.tar.gz"},
+		}
+		err := validator.ValidateUpdate(ctx, catalog, updated)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("spec.source.type: Invalid value: \"http\": field is immutable")))
+	})
+})
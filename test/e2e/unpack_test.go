@@ -3,7 +3,6 @@ package e2e
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -13,6 +12,8 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/operator-framework/catalogd/pkg/names"
 )
 
 const (
@@ -82,7 +83,7 @@ var _ = Describe("Catalog Unpacking", func() {
 				DefaultChannel: channel,
 				Name:           pkg,
 			}
-			err := c.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%s", catalog.Name, pkg)}, pack)
+			err := c.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, pkg)}, pack)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(pack.Spec).To(Equal(expectedPackSpec))
 
@@ -92,6 +93,7 @@ var _ = Describe("Catalog Unpacking", func() {
 				Catalog: v1.LocalObjectReference{
 					Name: catalogName,
 				},
+				Name:    bundle,
 				Package: pkg,
 				Image:   bundleImage,
 				Properties: []catalogd.Property{
@@ -101,7 +103,7 @@ var _ = Describe("Catalog Unpacking", func() {
 					},
 				},
 			}
-			err = c.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%s", catalog.Name, bundle)}, bm)
+			err = c.Get(ctx, types.NamespacedName{Name: names.ForCatalogObject(catalog.Name, bundle)}, bm)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(bm.Spec).To(Equal(expectedBMSpec))
 		})